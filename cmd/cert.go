@@ -0,0 +1,94 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/praromvik/praromvik/pkg/certificate"
+	"github.com/praromvik/praromvik/pkg/ledger"
+
+	"github.com/spf13/cobra"
+)
+
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Work with course certificates",
+}
+
+var certVerifyCmd = &cobra.Command{
+	Use:   "verify <proof.json>",
+	Short: "Check that a certificate is in the certificate ledger",
+	Long: `Checks the inclusion proof of a certificate, as downloaded from /verify/{serial}/proof, against the public key
+of the ledger (served at /api/ledger/key). Nothing is looked up: the proof & the key are all it takes.
+With --pdf, it also checks that the PDF is the one the certificate was issued with.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var proof certificate.Proof
+		if err := json.Unmarshal(data, &proof); err != nil {
+			return fmt.Errorf("invalid proof: %w", err)
+		}
+		key, err := ledger.LoadPublicKey(certPublicKey)
+		if err != nil {
+			return err
+		}
+		var document []byte
+		if certPDF != "" {
+			if document, err = os.ReadFile(certPDF); err != nil {
+				return err
+			}
+		}
+		if err := proof.Verify(key, document); err != nil {
+			return fmt.Errorf("the certificate %s is NOT verified: %w", proof.Certificate.Serial, err)
+		}
+		c := proof.Certificate
+		fmt.Printf("The certificate %s is verified: issued to %s for %s (%s) on %s.\n", c.Serial, c.Name, c.CourseTitle, c.Course, c.IssuedAt)
+		fmt.Printf("It is entry %d of the ledger checkpointed at %d entries on %s, with the root %s.\n",
+			proof.Ledger.LeafIndex, proof.Ledger.Checkpoint.Size, proof.Ledger.Checkpoint.CreatedAt.Format("2006-01-02 15:04:05 MST"), proof.Ledger.Checkpoint.Root)
+		if document != nil {
+			fmt.Printf("%s is its PDF.\n", certPDF)
+		}
+		return nil
+	},
+}
+
+var (
+	certPublicKey string
+	certPDF       string
+)
+
+func init() {
+	rootCmd.AddCommand(certCmd)
+	certCmd.AddCommand(certVerifyCmd)
+	certVerifyCmd.Flags().StringVar(&certPublicKey, "public-key", "", "Public key of the ledger (PEM).")
+	certVerifyCmd.Flags().StringVar(&certPDF, "pdf", "", "PDF of the certificate to check as well.")
+	_ = certVerifyCmd.MarkFlagRequired("public-key")
+}
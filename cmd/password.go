@@ -0,0 +1,90 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/praromvik/praromvik/pkg/password"
+
+	"github.com/spf13/cobra"
+)
+
+var passwordCmd = &cobra.Command{
+	Use:   "password",
+	Short: "Tools for password hashing",
+}
+
+var passwordBenchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "Measure argon2id on this machine to choose the hashing parameters",
+	Long: `Hashes a password with the given memory & parallelism, raising the iterations until one hash takes
+at least the target duration. The last line is the recommendation, printed as a --password-config snippet.`,
+	Example: `  praromvik password benchmark
+  praromvik password benchmark --memory 131072 --parallelism 4 --target 250ms`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		measurements, err := password.Benchmark(benchMemory, benchParallelism, benchTarget)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "MEMORY (KiB)\tITERATIONS\tPARALLELISM\tDURATION")
+		for _, m := range measurements {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%v\n", m.Params.Memory, m.Params.Iterations, m.Params.Parallelism, m.Duration.Round(time.Millisecond))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		recommended := measurements[len(measurements)-1]
+		if recommended.Duration < benchTarget {
+			fmt.Println("\nThe target wasn't reached, consider more memory.")
+		}
+		snippet, err := json.MarshalIndent(map[string]password.Params{"argon2": recommended.Params}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nRecommended:\n%s\n", snippet)
+		return nil
+	},
+}
+
+var (
+	benchMemory      uint32
+	benchParallelism uint8
+	benchTarget      time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(passwordCmd)
+	passwordCmd.AddCommand(passwordBenchmarkCmd)
+	passwordBenchmarkCmd.Flags().Uint32Var(&benchMemory, "memory", password.DefaultParams.Memory, "Memory used by argon2id, in KiB.")
+	passwordBenchmarkCmd.Flags().Uint8Var(&benchParallelism, "parallelism", password.DefaultParams.Parallelism, "Number of argon2id lanes.")
+	passwordBenchmarkCmd.Flags().DurationVar(&benchTarget, "target", 500*time.Millisecond, "How long a single hash should take at least.")
+}
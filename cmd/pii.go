@@ -0,0 +1,90 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/pkg/pii"
+
+	"github.com/spf13/cobra"
+)
+
+var piiCmd = &cobra.Command{
+	Use:   "pii",
+	Short: "Manage the encryption keys of personal data",
+}
+
+var piiNewKeyCmd = &cobra.Command{
+	Use:   "new-key",
+	Short: "Add a new key & make it the current one, creating the key file if needed",
+	Long: `Adds a new key encryption key to the key file. New values are encrypted with it right away,
+older ones stay readable. Run "praromvik pii reencrypt" afterwards to move the stored data to the new key.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := pii.LoadKeyFile(piiKeyFileFlag)
+		if errors.Is(err, os.ErrNotExist) {
+			provider, err = &pii.LocalKeyProvider{}, nil
+		}
+		if err != nil {
+			return err
+		}
+		id, err := provider.AddKey()
+		if err != nil {
+			return err
+		}
+		if err := provider.Save(piiKeyFileFlag); err != nil {
+			return err
+		}
+		fmt.Printf("Added key %s to %s\n", id, piiKeyFileFlag)
+		return nil
+	},
+}
+
+var piiReencryptCmd = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "Encrypt the personal fields of every user with the current key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := pii.LoadKeyFile(piiKeyFileFlag)
+		if err != nil {
+			return err
+		}
+		user.UsePIICipher(pii.NewCipher(provider))
+		updated, err := user.ReencryptAll()
+		fmt.Printf("Re-encrypted %d users\n", updated)
+		return err
+	},
+}
+
+var piiKeyFileFlag string
+
+func init() {
+	rootCmd.AddCommand(piiCmd)
+	piiCmd.AddCommand(piiNewKeyCmd, piiReencryptCmd)
+	piiCmd.PersistentFlags().StringVar(&piiKeyFileFlag, "key-file", "", "Key file of the personal data encryption keys.")
+	_ = piiCmd.MarkPersistentFlagRequired("key-file")
+}
@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	middleware "github.com/praromvik/praromvik/pkg/middileware"
+	"github.com/praromvik/praromvik/pkg/policy"
+
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the authorization policy",
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check whether a user is allowed to perform an action on a resource",
+	Example: `  praromvik policy check --user alice --action update --resource course/advanced-golang
+  praromvik policy check --user bob --action create --resource lesson/advanced-golang
+  praromvik policy check --user bob --action read --resource user/alice
+  praromvik policy check --user carol --action read --resource progress/advanced-golang/alice`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := policy.NewEngine(checkPolicyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load policy: %w", err)
+		}
+		u := &user.User{UserName: checkUser}
+		if err := u.FetchAuthDataFromDB(); err != nil {
+			return fmt.Errorf("failed to get user '%s': %w", checkUser, err)
+		}
+		kind, id, courseRef, err := parseResource(checkResource)
+		if err != nil {
+			return err
+		}
+		resource, err := middleware.LoadResource(kind, id, courseRef)
+		if err != nil {
+			return fmt.Errorf("failed to get resource '%s': %w", checkResource, err)
+		}
+
+		subject := policy.Subject{Name: u.UserName}
+		for role := range u.ActiveRoles(time.Now()) {
+			subject.Roles = append(subject.Roles, role)
+		}
+		sort.Strings(subject.Roles)
+		decision := engine.Decide(subject, checkAction, resource)
+		if !decision.Allowed {
+			fmt.Printf("DENIED: %s (roles %v) may not %s %s\n", u.UserName, subject.Roles, checkAction, checkResource)
+			cmd.SilenceUsage = true
+			return fmt.Errorf("denied")
+		}
+		fmt.Printf("ALLOWED: %s (roles %v) may %s %s\n", u.UserName, subject.Roles, checkAction, checkResource)
+		fmt.Printf("matched rule: roles=%v resource=%s actions=%v conditions=%v\n",
+			decision.Rule.Roles, decision.Rule.Resource, decision.Rule.Actions, decision.Rule.Conditions)
+		return nil
+	},
+}
+
+var (
+	checkPolicyFile string
+	checkUser       string
+	checkAction     string
+	checkResource   string
+)
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+	policyCheckCmd.Flags().StringVar(&checkPolicyFile, "policy-file", "", "Authorization policy file. The built-in policy is used if empty.")
+	policyCheckCmd.Flags().StringVar(&checkUser, "user", "", "Name of the user performing the action.")
+	policyCheckCmd.Flags().StringVar(&checkAction, "action", "", "Action to check, for example read, create, update, delete.")
+	policyCheckCmd.Flags().StringVar(&checkResource, "resource", "", "Resource as <kind>[/<id>], or <kind>/<courseRef>[/<id>] for resources within a course.")
+	_ = policyCheckCmd.MarkFlagRequired("user")
+	_ = policyCheckCmd.MarkFlagRequired("action")
+	_ = policyCheckCmd.MarkFlagRequired("resource")
+}
+
+// parseResource splits a resource reference into its kind, id & owning course.
+func parseResource(ref string) (kind string, id string, courseRef string, err error) {
+	parts := strings.Split(ref, "/")
+	kind = parts[0]
+	switch kind {
+	case utils.ResourceCourse, utils.ResourceUser, utils.ResourceRole:
+		if len(parts) > 2 {
+			return "", "", "", fmt.Errorf("expected %s[/<id>], got '%s'", kind, ref)
+		}
+		if len(parts) == 2 {
+			id = parts[1]
+		}
+	case utils.ResourceLesson, utils.ResourceContent, utils.ResourceSubmission, utils.ResourceExam, utils.ResourceQuestion,
+		utils.ResourceProgress:
+		if len(parts) < 2 || len(parts) > 3 {
+			return "", "", "", fmt.Errorf("expected %s/<courseRef>[/<id>], got '%s'", kind, ref)
+		}
+		courseRef = parts[1]
+		if len(parts) == 3 {
+			id = parts[2]
+		}
+	default:
+		return "", "", "", fmt.Errorf("unknown resource kind '%s'", kind)
+	}
+	return kind, id, courseRef, nil
+}
@@ -0,0 +1,60 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import "testing"
+
+func TestParseResource(t *testing.T) {
+	tests := []struct {
+		name      string
+		ref       string
+		kind      string
+		id        string
+		courseRef string
+		valid     bool
+	}{
+		{name: "Course", ref: "course/advanced-golang", kind: "course", id: "advanced-golang", valid: true},
+		{name: "Courses", ref: "course", kind: "course", valid: true},
+		{name: "Lesson", ref: "lesson/advanced-golang/introduction", kind: "lesson", id: "introduction", courseRef: "advanced-golang", valid: true},
+		{name: "Progress", ref: "progress/advanced-golang/alice", kind: "progress", id: "alice", courseRef: "advanced-golang", valid: true},
+		{name: "ProgressWithoutCourse", ref: "progress", valid: false},
+		{name: "UserWithCourse", ref: "user/advanced-golang/alice", valid: false},
+		{name: "UnknownKind", ref: "grade/advanced-golang", valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kind, id, courseRef, err := parseResource(test.ref)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v", test.valid, err)
+			}
+			if !test.valid {
+				return
+			}
+			if kind != test.kind || id != test.id || courseRef != test.courseRef {
+				t.Fatalf("expected %v/%v/%v, got %v/%v/%v", test.kind, test.courseRef, test.id, kind, courseRef, id)
+			}
+		})
+	}
+}
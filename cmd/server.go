@@ -35,20 +35,129 @@ import (
 	"syscall"
 	"time"
 
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/pkg/account"
+	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/certificate"
+	"github.com/praromvik/praromvik/pkg/clientip"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	"github.com/praromvik/praromvik/pkg/ledger"
+	"github.com/praromvik/praromvik/pkg/mailer"
+	middleware "github.com/praromvik/praromvik/pkg/middileware"
+	"github.com/praromvik/praromvik/pkg/password"
+	"github.com/praromvik/praromvik/pkg/pii"
+	"github.com/praromvik/praromvik/pkg/policy"
+	"github.com/praromvik/praromvik/pkg/security"
 	"github.com/praromvik/praromvik/routers"
 )
 
 type Server struct {
 	router http.Handler
+	policy *policy.Engine
 }
 
 func New() (*Server, error) {
+	if err := checkIntervals(); err != nil {
+		return nil, err
+	}
+	engine, err := policy.NewEngine(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+	if appEnv == "" {
+		appEnv = os.Getenv("APP_ENV")
+	}
+	if appEnv == "" {
+		appEnv = security.Development
+	}
+	sec, err := security.Load(appEnv, securityConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load security config: %w", err)
+	}
+	resolver, err := clientip.NewResolver(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	binding, err := clientip.ParseBinding(sessionBinding)
+	if err != nil {
+		return nil, err
+	}
+	auth.UseClientIPResolver(resolver)
+	auth.UseSessionBinding(binding)
+
+	if err := configureAccounts(); err != nil {
+		return nil, err
+	}
+	middleware.AllowImpersonatedWrites(impersonationAllowWrites)
+	enrollment.UseClaimWindow(waitlistClaimWindow)
+	template, err := certificate.LoadTemplate(certificateTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate template: %w", err)
+	}
+	certificate.UseTemplate(template)
+	if ledgerKeyFile != "" {
+		key, err := ledger.LoadPrivateKey(ledgerKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ledger key: %w", err)
+		}
+		enrollment.UseLedgerKey(key)
+	} else {
+		log.Println("no --ledger-key-file given, the certificate ledger isn't checkpointed")
+	}
+
 	app := &Server{
-		router: routers.LoadRoutes(),
+		router: routers.LoadRoutes(engine, sec),
+		policy: engine,
 	}
 	return app, nil
 }
 
+// checkIntervals refuses the intervals of the background loops which can't tick, zero or negative.
+func checkIntervals() error {
+	for _, flag := range []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"policy-reload-interval", policyReloadInterval},
+		{"deletion-sweep-interval", deletionSweepInterval},
+		{"waitlist-sweep-interval", waitlistSweepInterval},
+		{"ledger-checkpoint-interval", ledgerCheckpointInterval},
+		{"exam-sweep-interval", examSweepInterval},
+	} {
+		if flag.interval <= 0 {
+			return fmt.Errorf("--%s must be positive, got %s", flag.name, flag.interval)
+		}
+	}
+	return nil
+}
+
+// configureAccounts applies the flags on how accounts are stored & how users are reached,
+// shared by the server & the commands working on users.
+func configureAccounts() error {
+	passwords, err := password.LoadConfig(passwordConfigFile)
+	if err != nil {
+		return err
+	}
+	user.UsePasswordConfig(passwords)
+	if piiKeyFile != "" {
+		provider, err := pii.LoadKeyFile(piiKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load PII keys: %w", err)
+		}
+		user.UsePIICipher(pii.NewCipher(provider))
+	} else {
+		log.Println("no --pii-key-file given, personal fields are stored in plaintext")
+	}
+	hutils.UsePublicURL(publicURL)
+	if smtpAddr != "" {
+		mailer.Use(mailer.SMTPMailer{Addr: smtpAddr, Username: smtpUsername, Password: os.Getenv("SMTP_PASSWORD"), From: mailFrom})
+	} else {
+		log.Println("no --smtp-addr given, mails are only logged")
+	}
+	return nil
+}
+
 func (a *Server) Start(ctx context.Context) error {
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%v", port),
@@ -57,6 +166,11 @@ func (a *Server) Start(ctx context.Context) error {
 
 	// Server run context
 	serverCtx, serverStopCtx := context.WithCancel(ctx)
+	go a.policy.Watch(serverCtx, policyReloadInterval)
+	go account.Sweep(serverCtx, deletionSweepInterval)
+	go enrollment.Sweep(serverCtx, waitlistSweepInterval)
+	go enrollment.SweepLedger(serverCtx, ledgerCheckpointInterval)
+	go enrollment.SweepExams(serverCtx, examSweepInterval)
 
 	// Listen for syscall signals for process to interrupt/quit
 	sig := make(chan os.Signal, 1)
@@ -29,8 +29,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"time"
+
+	"github.com/praromvik/praromvik/pkg/clientip"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // startServerCmd represents the startServer command
@@ -51,9 +55,58 @@ var startServerCmd = &cobra.Command{
 	},
 }
 
-var port string
+var (
+	port                 string
+	policyFile           string
+	policyReloadInterval time.Duration
+	appEnv               string
+	securityConfigFile   string
+	trustedProxies       []string
+	sessionBinding       string
+	passwordConfigFile   string
+	piiKeyFile           string
+	certificateTemplate  string
+	ledgerKeyFile        string
+
+	impersonationAllowWrites bool
+	deletionSweepInterval    time.Duration
+	waitlistClaimWindow      time.Duration
+	waitlistSweepInterval    time.Duration
+	ledgerCheckpointInterval time.Duration
+	examSweepInterval        time.Duration
+
+	publicURL    string
+	smtpAddr     string
+	smtpUsername string
+	mailFrom     string
+)
 
 func init() {
 	rootCmd.AddCommand(startServerCmd)
 	startServerCmd.PersistentFlags().StringVarP(&port, "port", "p", "3030", "Set server listening port address.")
+	startServerCmd.PersistentFlags().StringVar(&policyFile, "policy-file", "", "Authorization policy file. The built-in policy is used if empty.")
+	startServerCmd.PersistentFlags().DurationVar(&policyReloadInterval, "policy-reload-interval", 10*time.Second, "How often to check the policy file for changes.")
+	startServerCmd.PersistentFlags().StringVar(&appEnv, "env", "", "Environment (development, staging or production) picking the default CORS & security headers. Defaults to $APP_ENV, then development.")
+	startServerCmd.PersistentFlags().StringSliceVar(&trustedProxies, "trusted-proxies", nil, "Addresses or CIDR ranges of the proxies whose X-Forwarded-For & Forwarded headers are trusted.")
+	startServerCmd.PersistentFlags().StringVar(&sessionBinding, "session-binding", string(clientip.BindStrictIP), "How sessions are bound to the client: strict-ip, subnet, user-agent or none.")
+	startServerCmd.PersistentFlags().BoolVar(&impersonationAllowWrites, "impersonation-allow-writes", false, "Let admins make state-changing requests while impersonating a user.")
+	startServerCmd.PersistentFlags().DurationVar(&deletionSweepInterval, "deletion-sweep-interval", time.Hour, "How often to purge the accounts whose deletion grace period is over.")
+	startServerCmd.PersistentFlags().DurationVar(&waitlistClaimWindow, "waitlist-claim-window", 48*time.Hour, "How long a user promoted from a waitlist has to claim their seat.")
+	startServerCmd.PersistentFlags().DurationVar(&waitlistSweepInterval, "waitlist-sweep-interval", 5*time.Minute, "How often to expire unclaimed waitlist offers & promote waitlists.")
+	startServerCmd.PersistentFlags().StringVar(&certificateTemplate, "certificate-template", "", "JSON file laying out the course certificates. The built-in template is used if empty.")
+	startServerCmd.PersistentFlags().StringVar(&ledgerKeyFile, "ledger-key-file", "", "Ed25519 private key (PKCS #8 PEM) signing the checkpoints of the certificate ledger. No checkpoint is made if empty.")
+	startServerCmd.PersistentFlags().DurationVar(&ledgerCheckpointInterval, "ledger-checkpoint-interval", time.Hour, "How often to checkpoint the certificate ledger.")
+	startServerCmd.PersistentFlags().DurationVar(&examSweepInterval, "exam-sweep-interval", time.Minute, "How often to submit the exam attempts whose time ran out.")
+	startServerCmd.PersistentFlags().StringVar(&securityConfigFile, "security-config", "", "JSON file overriding the allowed origins & security headers of the environment.")
+	addAccountFlags(startServerCmd.PersistentFlags())
+}
+
+// addAccountFlags adds the flags read by configureAccounts.
+func addAccountFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&passwordConfigFile, "password-config", "", "JSON file with the argon2id parameters & the password policy. Defaults are used if empty.")
+	flags.StringVar(&piiKeyFile, "pii-key-file", "", "Key file encrypting the personal fields of users. They are stored in plaintext if empty.")
+	flags.StringVar(&publicURL, "public-url", "http://localhost:3030", "Address the API is reached at, used in the links mailed to users.")
+	flags.StringVar(&smtpAddr, "smtp-addr", "", "SMTP server (host:port) to send mails through. Mails are only logged if empty.")
+	flags.StringVar(&smtpUsername, "smtp-username", "", "SMTP username. The password is read from $SMTP_PASSWORD.")
+	flags.StringVar(&mailFrom, "mail-from", "Praromvik <no-reply@praromvik.com>", "Sender of the mails.")
 }
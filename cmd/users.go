@@ -0,0 +1,110 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/pkg/account"
+	"github.com/praromvik/praromvik/pkg/roster"
+
+	"github.com/spf13/cobra"
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage user accounts",
+}
+
+var usersImportCmd = &cobra.Command{
+	Use:   "import <users.csv>",
+	Short: "Create the accounts of the users listed in a CSV file",
+	Long: `Creates an account for every row of the CSV file, checked like a sign up, and prints a JSON report of every row.
+The header row names the columns: userName & email are required, phone, displayName & role are optional.
+Imported users choose their password through the invitation mailed to them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configureAccounts(); err != nil {
+			return err
+		}
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		rows, err := roster.Parse(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", args[0], err)
+		}
+		report, err := account.Import(rows, account.ImportOptions{
+			InvitedBy: importInvitedBy,
+			CourseID:  importCourse,
+			Invite:    importInvite,
+			DryRun:    importDryRun,
+			Link:      hutils.Link,
+		})
+		if err != nil {
+			return err
+		}
+		if !importDryRun {
+			audit.Log(&audit.Event{
+				Type:    audit.UserImport,
+				Actor:   importInvitedBy,
+				Details: map[string]string{"course": importCourse, "created": strconv.Itoa(report.Created), "failed": strconv.Itoa(report.Failed)},
+			})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+		if report.Failed > 0 {
+			return fmt.Errorf("%d of %d rows failed", report.Failed, len(report.Rows))
+		}
+		return nil
+	},
+}
+
+var (
+	importCourse    string
+	importInvite    bool
+	importDryRun    bool
+	importInvitedBy string
+)
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersImportCmd)
+	usersImportCmd.Flags().StringVar(&importCourse, "course", "", "Course to enroll the imported users in.")
+	usersImportCmd.Flags().BoolVar(&importInvite, "invite", true, "Mail the invitations. Otherwise, their links are in the report.")
+	usersImportCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only validate the rows.")
+	usersImportCmd.Flags().StringVar(&importInvitedBy, "invited-by", "admin", "Admin the import is recorded for.")
+	addAccountFlags(usersImportCmd.Flags())
+}
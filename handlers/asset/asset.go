@@ -0,0 +1,51 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package asset
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/praromvik/praromvik/models/asset"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Asset struct{}
+
+// Get serves the stored file. Assets are immutable, a new upload always gets a new id.
+func (a Asset) Get(w http.ResponseWriter, r *http.Request) {
+	stored, err := asset.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		perror.HandleError(w, http.StatusNotFound, "Asset not found", err)
+		return
+	}
+	w.Header().Set("Content-Type", stored.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(stored.Data)))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(stored.Data)
+}
@@ -0,0 +1,197 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package badge
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	pcertificate "github.com/praromvik/praromvik/pkg/certificate"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/openbadges"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Badge serves the certificates as Open Badges. Everything here is public, as badge verifiers fetch it.
+type Badge struct{}
+
+// Issuer returns the profile of the issuer of the badges.
+func (b Badge) Issuer(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, enrollment.BadgeIssuer(hutils.Link).ProfileV2())
+}
+
+// Achievement returns the badge class of completing the course.
+func (b Badge) Achievement(w http.ResponseWriter, r *http.Request) {
+	a, ok := courseAchievement(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, a.BadgeClassV2(enrollment.BadgeIssuer(hutils.Link)))
+}
+
+// Image draws the badge of the course, as png or svg.
+func (b Badge) Image(w http.ResponseWriter, r *http.Request) {
+	a, ok := courseAchievement(w, r)
+	if !ok {
+		return
+	}
+	writeImage(w, r, a, func(image []byte, svg bool) ([]byte, error) { return image, nil })
+}
+
+// Assertion returns the hosted 2.0 assertion of the certificate, which verifiers fetch to verify it.
+func (b Badge) Assertion(w http.ResponseWriter, r *http.Request) {
+	award, ok := certificateBadge(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, award.AssertionV2())
+}
+
+// AssertionImage is the image of the badge with the 2.0 assertion baked in, to be shared or uploaded to a backpack.
+func (b Badge) AssertionImage(w http.ResponseWriter, r *http.Request) {
+	award, ok := certificateBadge(w, r)
+	if !ok {
+		return
+	}
+	writeImage(w, r, award.Achievement, func(image []byte, svg bool) ([]byte, error) {
+		if svg {
+			return openbadges.BakeSVGAssertion(image, award.ID)
+		}
+		return openbadges.BakePNG(image, openbadges.KeywordV2, award.ID)
+	})
+}
+
+// Credential returns the certificate as a 3.0 credential, signed as a VC-JWT.
+func (b Badge) Credential(w http.ResponseWriter, r *http.Request) {
+	_, signed, ok := certificateCredential(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/vc+jwt")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(signed))
+}
+
+// CredentialImage is the image of the badge with the signed 3.0 credential baked in.
+func (b Badge) CredentialImage(w http.ResponseWriter, r *http.Request) {
+	award, signed, ok := certificateCredential(w, r)
+	if !ok {
+		return
+	}
+	writeImage(w, r, award.Achievement, func(image []byte, svg bool) ([]byte, error) {
+		if svg {
+			return openbadges.BakeSVGCredential(image, signed)
+		}
+		return openbadges.BakePNG(image, openbadges.KeywordV3, signed)
+	})
+}
+
+func courseAchievement(w http.ResponseWriter, r *http.Request) (openbadges.Achievement, bool) {
+	a, err := enrollment.CourseAchievement(chi.URLParam(r, "id"), hutils.Link)
+	if errors.Is(err, course.ErrCourseNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return a, false
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting course", err)
+		return a, false
+	}
+	return a, true
+}
+
+func certificateBadge(w http.ResponseWriter, r *http.Request) (*openbadges.Award, bool) {
+	award, err := enrollment.CertificateBadge(pcertificate.NormalizeSerial(chi.URLParam(r, "serial")), hutils.Link)
+	if errors.Is(err, certificate.ErrCertificateNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "No certificate was issued with this serial", err)
+		return nil, false
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting badge", err)
+		return nil, false
+	}
+	return award, true
+}
+
+func certificateCredential(w http.ResponseWriter, r *http.Request) (*openbadges.Award, string, bool) {
+	award, ok := certificateBadge(w, r)
+	if !ok {
+		return nil, "", false
+	}
+	signed, err := enrollment.SignCredential(*award, hutils.Link)
+	if errors.Is(err, enrollment.ErrNoLedgerKey) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return nil, "", false
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on signing credential", err)
+		return nil, "", false
+	}
+	return award, signed, true
+}
+
+// writeImage draws the badge of the achievement in the format of the path, then bakes it.
+func writeImage(w http.ResponseWriter, r *http.Request, a openbadges.Achievement, bake func(image []byte, svg bool) ([]byte, error)) {
+	var (
+		image       []byte
+		contentType string
+		err         error
+	)
+	switch chi.URLParam(r, "format") {
+	case "png":
+		image, err = openbadges.RenderPNG(a.ID)
+		contentType = "image/png"
+	case "svg":
+		image = openbadges.RenderSVG(a.ID, a.Name)
+		contentType = "image/svg+xml"
+	default:
+		perror.HandleError(w, http.StatusNotFound, "", errors.New("badges are drawn as png or svg"))
+		return
+	}
+	if err == nil {
+		image, err = bake(image, contentType == "image/svg+xml")
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on drawing badge", err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(image)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(image)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certificate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/certificate"
+	mledger "github.com/praromvik/praromvik/models/ledger"
+	"github.com/praromvik/praromvik/pkg/auth"
+	pcertificate "github.com/praromvik/praromvik/pkg/certificate"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/ledger"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Certificate struct{}
+
+type issuedCertificate struct {
+	Valid bool `json:"valid"`
+	*certificate.Certificate
+	Document string `json:"document,omitempty"` // where to download the PDF
+	Badges   badges `json:"badges"`
+}
+
+// badges are where to get the certificate as Open Badges, with the images they are baked into.
+type badges struct {
+	Assertion     string `json:"assertion"` // 2.0, hosted
+	AssertionPNG  string `json:"assertionPng"`
+	AssertionSVG  string `json:"assertionSvg"`
+	Credential    string `json:"credential,omitempty"` // 3.0, signed with the ledger key
+	CredentialPNG string `json:"credentialPng,omitempty"`
+	CredentialSVG string `json:"credentialSvg,omitempty"`
+}
+
+// Verify publicly confirms a certificate by its serial, with whom it was issued to, for which course & when.
+func (c Certificate) Verify(w http.ResponseWriter, r *http.Request) {
+	issued, err := certificate.Get(pcertificate.NormalizeSerial(chi.URLParam(r, "serial")))
+	if errors.Is(err, certificate.ErrCertificateNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "No certificate was issued with this serial", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting certificate", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(describe(issued)); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// Proof returns the inclusion proof of a certificate in the ledger, to be checked offline with `praromvik cert verify`.
+func (c Certificate) Proof(w http.ResponseWriter, r *http.Request) {
+	proof, err := enrollment.CertificateProof(pcertificate.NormalizeSerial(chi.URLParam(r, "serial")))
+	if errors.Is(err, certificate.ErrCertificateNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "No certificate was issued with this serial", err)
+		return
+	}
+	if errors.Is(err, enrollment.ErrNotCheckpointed) {
+		perror.HandleError(w, http.StatusConflict, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on proving certificate", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.proof.json"`, proof.Certificate.Serial))
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(proof); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// LedgerKey serves the public key the ledger checkpoints are signed with, as PEM.
+func (c Certificate) LedgerKey(w http.ResponseWriter, r *http.Request) {
+	key, err := enrollment.LedgerPublicKey()
+	if err != nil {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return
+	}
+	data, err := ledger.MarshalPublicKey(key)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Header().Set("X-Praromvik-Key-Id", ledger.KeyID(key))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// LedgerCheckpoint returns the latest signed head of the ledger, for anyone to keep & compare.
+func (c Certificate) LedgerCheckpoint(w http.ResponseWriter, r *http.Request) {
+	checkpoint, err := mledger.LatestCheckpoint()
+	if errors.Is(err, mledger.ErrNoCheckpoint) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting checkpoint", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(checkpoint); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// List returns the certificates issued to the signed-in user.
+func (c Certificate) List(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	certificates, err := certificate.ListByUser(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing certificates", err)
+		return
+	}
+	issued := make([]issuedCertificate, 0, len(certificates))
+	for i := range certificates {
+		issued = append(issued, describe(&certificates[i]))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(issued); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func describe(c *certificate.Certificate) issuedCertificate {
+	issued := issuedCertificate{Valid: true, Certificate: c}
+	if c.Asset != "" {
+		issued.Document = hutils.Link("/api/assets/" + c.Asset)
+	}
+	assertion := hutils.Link("/api/badges/assertions/" + c.Serial)
+	issued.Badges = badges{Assertion: assertion, AssertionPNG: assertion + "/badge.png", AssertionSVG: assertion + "/badge.svg"}
+	if _, err := enrollment.LedgerPublicKey(); err == nil {
+		credential := hutils.Link("/api/badges/credentials/" + c.Serial)
+		issued.Badges.Credential, issued.Badges.CredentialPNG, issued.Badges.CredentialSVG = credential, credential+"/badge.png", credential+"/badge.svg"
+	}
+	return issued
+}
@@ -46,6 +46,25 @@ func (c *Content) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	c.CourseRef = chi.URLParam(r, "courseRef")
+	if c.Type == course.ContentQuiz {
+		if c.Quiz == nil {
+			perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("a quiz content needs the pools to draw its questions from"))
+			return
+		}
+		if err := c.Quiz.Validate(); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "", err)
+			return
+		}
+		seed, err := newQuizSeed()
+		if err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "Error on seeding quiz", err)
+			return
+		}
+		c.Quiz.Seed = seed
+	} else if c.Quiz != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("only quiz contents draw questions"))
+		return
+	}
 	errCode, err := course.ValidateNameUniqueness(c.Content)
 	if err != nil {
 		perror.HandleError(w, errCode, "", err)
@@ -26,13 +26,20 @@ package course
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"reflect"
 	"slices"
+	"time"
 
+	hutils "github.com/praromvik/praromvik/handlers/utils"
 	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/waitlist"
 	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/enrollment"
 	perror "github.com/praromvik/praromvik/pkg/error"
 
 	"github.com/go-chi/chi/v5"
@@ -47,6 +54,12 @@ func (c *Course) Create(w http.ResponseWriter, r *http.Request) {
 		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
 		return
 	}
+	if err := c.ValidateDates(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	// Students only join through enrollment, which enforces the capacity.
+	c.Students, c.Reserved = nil, nil
 	errCode, err := course.ValidateNameUniqueness(c.Course)
 	if err != nil {
 		perror.HandleError(w, errCode, "", err)
@@ -85,9 +98,20 @@ func (c *Course) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewer, err := newCourseViewer(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	data, err := hutils.ProjectList(*documents.(*[]course.Course), viewer.relationsTo)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
 	// Encode the documents to JSON and send the response
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(documents.(*[]course.Course)); err != nil {
+	if err := json.NewEncoder(w).Encode(data); err != nil {
 		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
 		return
 	}
@@ -111,9 +135,20 @@ func (c *Course) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewer, err := newCourseViewer(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	data, err := hutils.Project(document, viewer.relationsTo(*document.(*course.Course))...)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
 	// Encode the document to JSON and send the response
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(document.(*course.Course)); err != nil {
+	if err := json.NewEncoder(w).Encode(data); err != nil {
 		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
 		return
 	}
@@ -127,11 +162,21 @@ func (c *Course) Update(w http.ResponseWriter, r *http.Request) {
 		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
 		return
 	}
-	if err := course.Update(c.Course); err != nil {
-		fmt.Println(err)
+	if err := c.ValidateDates(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if err := course.UpdateCourse(c.Course); errors.Is(err, course.ErrCourseNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return
+	} else if err != nil {
 		perror.HandleError(w, http.StatusBadRequest, "Error on updating course", err)
 		return
 	}
+	// A larger capacity frees seats for the waitlist.
+	if err := enrollment.Promote(c.CourseId, time.Now()); err != nil {
+		log.Printf("failed to promote the waitlist of %s: %v", c.CourseId, err)
+	}
 }
 
 func (c *Course) Delete(w http.ResponseWriter, r *http.Request) {
@@ -139,6 +184,25 @@ func (c *Course) Delete(w http.ResponseWriter, r *http.Request) {
 	c.CourseId = chi.URLParam(r, "id")
 	if err := course.Delete(c.Course); err != nil {
 		perror.HandleError(w, http.StatusBadRequest, "Error on deleting course.", err)
+		return
+	}
+	if err := user.ForgetCourse(c.CourseId); err != nil {
+		log.Printf("failed to remove course %s from the courses of its students: %v", c.CourseId, err)
+	}
+	if err := waitlist.DeleteCourse(c.CourseId); err != nil {
+		log.Printf("failed to delete the waitlist of course %s: %v", c.CourseId, err)
+	}
+	if err := course.DeleteCourseProgress(c.CourseId); err != nil {
+		log.Printf("failed to delete the progress in course %s: %v", c.CourseId, err)
+	}
+	if err := enrollment.DeleteCourseExams(c.CourseId); err != nil {
+		log.Printf("failed to delete the exams of course %s: %v", c.CourseId, err)
+	}
+	if err := course.DeleteBank(c.CourseId); err != nil {
+		log.Printf("failed to delete the question bank of course %s: %v", c.CourseId, err)
+	}
+	if err := course.DeleteCourseQuizDraws(c.CourseId); err != nil {
+		log.Printf("failed to delete the quizzes drawn in course %s: %v", c.CourseId, err)
 	}
 	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,142 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/waitlist"
+	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type enrollmentResponse struct {
+	Course    string `json:"course"`
+	UserName  string `json:"userName"`
+	SeatsLeft int    `json:"seatsLeft"`
+}
+
+// Enroll enrolls the signed-in user in the course.
+func (c *Course) Enroll(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	c.enroll(w, r, info.Name, info.Name)
+}
+
+// Unenroll drops the signed-in user from the course.
+func (c *Course) Unenroll(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	c.unenroll(w, r, info.Name, info.Name)
+}
+
+// EnrollStudent enrolls the user in the URL, on behalf of the staff of the course.
+func (c *Course) EnrollStudent(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	c.enroll(w, r, chi.URLParam(r, "userName"), info.Name)
+}
+
+// UnenrollStudent drops the user in the URL from the course, on behalf of the staff of the course.
+func (c *Course) UnenrollStudent(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	c.unenroll(w, r, chi.URLParam(r, "userName"), info.Name)
+}
+
+func (c *Course) enroll(w http.ResponseWriter, r *http.Request, userName string, actor string) {
+	enrolled, err := enrollment.Enroll(chi.URLParam(r, "id"), userName, time.Now())
+	if err != nil {
+		handleEnrollmentError(w, err)
+		return
+	}
+	afterEnrollment(w, r, enrolled, audit.CourseEnroll, userName, actor, 1)
+}
+
+func (c *Course) unenroll(w http.ResponseWriter, r *http.Request, userName string, actor string) {
+	left, err := enrollment.Unenroll(chi.URLParam(r, "id"), userName, time.Now())
+	if err != nil {
+		handleEnrollmentError(w, err)
+		return
+	}
+	afterEnrollment(w, r, left, audit.CourseUnenroll, userName, actor, -1)
+}
+
+// afterEnrollment audits the change & reports the seats left. c is the course as it was before the change.
+func afterEnrollment(w http.ResponseWriter, r *http.Request, c *course.Course, eventType string, userName string, actor string, delta int) {
+	audit.Log(&audit.Event{
+		Type:      eventType,
+		Actor:     actor,
+		Subject:   userName,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"course": c.CourseId},
+	})
+	seatsLeft := c.SeatsLeft()
+	if seatsLeft >= 0 {
+		seatsLeft = max(seatsLeft-delta, 0)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(enrollmentResponse{Course: c.CourseId, UserName: userName, SeatsLeft: seatsLeft}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func handleEnrollmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, course.ErrCourseNotFound), errors.Is(err, waitlist.ErrNotWaiting):
+		perror.HandleError(w, http.StatusNotFound, "", err)
+	case errors.Is(err, course.ErrCourseFull):
+		perror.HandleError(w, http.StatusConflict, "", fmt.Errorf("%w, join the waitlist instead", err))
+	case errors.Is(err, course.ErrCourseEnded), errors.Is(err, course.ErrAlreadyEnrolled),
+		errors.Is(err, course.ErrNotEnrolled), errors.Is(err, course.ErrNoReservedSeat),
+		errors.Is(err, waitlist.ErrAlreadyWaiting), errors.Is(err, waitlist.ErrOfferExpired),
+		errors.Is(err, enrollment.ErrSeatsLeft):
+		perror.HandleError(w, http.StatusConflict, "", err)
+	default:
+		perror.HandleError(w, http.StatusBadRequest, "Error on updating enrollment", err)
+	}
+}
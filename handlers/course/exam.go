@@ -0,0 +1,304 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/exam"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/assessment"
+	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Exam struct {
+	*exam.Exam
+}
+
+type answersRequest struct {
+	Answers []assessment.Answer `json:"answers"`
+}
+
+type attemptResponse struct {
+	*exam.Attempt
+	Questions  []assessment.Question `json:"questions,omitempty"` // while in progress, without their answers
+	ServerTime time.Time             `json:"serverTime"`          // to count down to the deadline from
+}
+
+func (e *Exam) Create(w http.ResponseWriter, r *http.Request) {
+	var ex exam.Exam
+	if err := json.NewDecoder(r.Body).Decode(&ex); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	ex.CourseRef = chi.URLParam(r, "courseRef")
+	if err := ex.Validate(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if err := exam.Create(&ex); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on creating exam", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ex); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// List returns the exams of the course. Only its staff see their questions.
+func (e *Exam) List(w http.ResponseWriter, r *http.Request) {
+	relations, ok := examRelations(w, r)
+	if !ok {
+		return
+	}
+	exams, err := exam.List(chi.URLParam(r, "courseRef"))
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing exams", err)
+		return
+	}
+	data, err := hutils.ProjectList(exams, func(exam.Exam) []hutils.Relation { return relations })
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// Get returns the exam. Only the staff of the course see its questions.
+func (e *Exam) Get(w http.ResponseWriter, r *http.Request) {
+	relations, ok := examRelations(w, r)
+	if !ok {
+		return
+	}
+	ex, ok := getExam(w, r)
+	if !ok {
+		return
+	}
+	data, err := hutils.Project(ex, relations...)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func (e *Exam) Update(w http.ResponseWriter, r *http.Request) {
+	var ex exam.Exam
+	if err := json.NewDecoder(r.Body).Decode(&ex); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	ex.ExamID, ex.CourseRef = chi.URLParam(r, "id"), chi.URLParam(r, "courseRef")
+	if err := ex.Validate(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if err := exam.Update(&ex); errors.Is(err, exam.ErrExamNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return
+	} else if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on updating exam", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Delete removes the exam with every attempt at it.
+func (e *Exam) Delete(w http.ResponseWriter, r *http.Request) {
+	ex, ok := getExam(w, r)
+	if !ok {
+		return
+	}
+	if err := enrollment.DeleteExam(ex); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on deleting exam", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Start starts the next attempt of the signed-in student, or resumes the one in progress.
+func (e *Exam) Start(w http.ResponseWriter, r *http.Request) {
+	e.attempt(w, r, func(ex *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error) {
+		return enrollment.StartExam(ex, student, now)
+	})
+}
+
+// Attempt returns the attempt of the signed-in student in progress, with its questions & saved answers.
+func (e *Exam) Attempt(w http.ResponseWriter, r *http.Request) {
+	e.attempt(w, r, func(ex *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error) {
+		return enrollment.CurrentAttempt(ex, student, now)
+	})
+}
+
+// SaveAnswers autosaves answers of the signed-in student, over the ones saved before.
+func (e *Exam) SaveAnswers(w http.ResponseWriter, r *http.Request) {
+	var req answersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	e.attempt(w, r, func(ex *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error) {
+		return enrollment.SaveAnswers(ex, student, req.Answers, now)
+	})
+}
+
+// Submit ends the attempt of the signed-in student, with the last answers if any, and returns its score.
+func (e *Exam) Submit(w http.ResponseWriter, r *http.Request) {
+	var req answersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	e.attempt(w, r, func(ex *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error) {
+		return enrollment.SubmitExam(ex, student, req.Answers, now)
+	})
+}
+
+// Attempts returns every attempt of the signed-in student at the exam.
+func (e *Exam) Attempts(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	writeAttempts(w, r, info.UUID)
+}
+
+// StudentAttempts returns the attempts of a student at the exam, for the staff of the course.
+func (e *Exam) StudentAttempts(w http.ResponseWriter, r *http.Request) {
+	student := &user.User{UserName: chi.URLParam(r, "userName")}
+	if err := student.FetchAndSetUUIDFromDB(); err != nil {
+		perror.HandleError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+	writeAttempts(w, r, student.UUID)
+}
+
+// attempt runs an action of the signed-in student on their attempt at the exam in the path, and writes the attempt.
+// The handler is shared by every request, so the exam is passed to the action rather than kept on it.
+func (e *Exam) attempt(w http.ResponseWriter, r *http.Request, action func(ex *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error)) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	ex, ok := getExam(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	a, err := action(ex, utils.Info{Name: info.Name, UUID: info.UUID}, now)
+	if err != nil {
+		handleExamError(w, err)
+		return
+	}
+	response := attemptResponse{Attempt: a, ServerTime: now.UTC()}
+	if a.Status == exam.StatusInProgress {
+		response.Questions = assessment.Redact(ex.Questions)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func writeAttempts(w http.ResponseWriter, r *http.Request, userUUID string) {
+	e, ok := getExam(w, r)
+	if !ok {
+		return
+	}
+	attempts, err := exam.ListAttempts(userUUID, e.ExamID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing attempts", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(attempts); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func getExam(w http.ResponseWriter, r *http.Request) (*exam.Exam, bool) {
+	e, err := exam.Get(chi.URLParam(r, "courseRef"), chi.URLParam(r, "id"))
+	if errors.Is(err, exam.ErrExamNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return nil, false
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting exam", err)
+		return nil, false
+	}
+	return e, true
+}
+
+// examRelations tells how the signed-in user relates to the exams of the course in the path.
+func examRelations(w http.ResponseWriter, r *http.Request) ([]hutils.Relation, bool) {
+	c, err := course.GetCourse(chi.URLParam(r, "courseRef"))
+	if err != nil {
+		perror.HandleError(w, http.StatusNotFound, "Error on getting course", err)
+		return nil, false
+	}
+	viewer, err := newCourseViewer(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return nil, false
+	}
+	return viewer.relationsTo(*c), true
+}
+
+func handleExamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, exam.ErrAttemptNotFound):
+		perror.HandleError(w, http.StatusNotFound, "", err)
+	case errors.Is(err, enrollment.ErrInvalidAnswer):
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+	case errors.Is(err, course.ErrNotEnrolled):
+		perror.HandleError(w, http.StatusForbidden, "", err)
+	case errors.Is(err, assessment.ErrNotOpen), errors.Is(err, assessment.ErrClosed),
+		errors.Is(err, assessment.ErrNoAttemptsLeft), errors.Is(err, enrollment.ErrAttemptOver),
+		errors.Is(err, exam.ErrAttemptConflict):
+		perror.HandleError(w, http.StatusConflict, "", err)
+	default:
+		perror.HandleError(w, http.StatusInternalServerError, "Error on updating attempt", err)
+	}
+}
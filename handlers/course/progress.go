@@ -0,0 +1,146 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/progress"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type recordResponse struct {
+	*course.Progress
+	Certificate *certificate.Certificate `json:"certificate,omitempty"` // issued as this completed the course
+}
+
+type progressResponse struct {
+	Course   string `json:"course"`
+	UserName string `json:"userName"`
+	*progress.Summary
+}
+
+// RecordProgress records a progress event of the signed-in user on the content. A quiz is only completed
+// by passing it. Completing the last content of the course issues the certificate of the course.
+func (c *Content) RecordProgress(w http.ResponseWriter, r *http.Request) {
+	var event progress.Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	if err := event.Validate(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	content, err := course.GetContent(chi.URLParam(r, "courseRef"), chi.URLParam(r, "id"))
+	if errors.Is(err, course.ErrContentNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting content", err)
+		return
+	}
+	if content.Type == course.ContentQuiz && event.Completes() {
+		perror.HandleError(w, http.StatusBadRequest, "", course.ErrQuizNotSubmitted)
+		return
+	}
+	record, err := course.RecordProgress(info.UUID, content, event)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on recording progress", err)
+		return
+	}
+	response := recordResponse{Progress: record}
+	if record.Completed {
+		response.Certificate = issueOnCompletion(content.CourseRef, info.Name)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// Progress returns the completion of the course, its lessons, and where to resume, for the signed-in user.
+func (c *Course) Progress(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	writeProgress(w, chi.URLParam(r, "courseRef"), info.Name, info.UUID)
+}
+
+// StudentProgress returns the progress of a student of the course, for its staff.
+func (c *Course) StudentProgress(w http.ResponseWriter, r *http.Request) {
+	student := &user.User{UserName: chi.URLParam(r, "userName")}
+	if err := student.FetchAndSetUUIDFromDB(); err != nil {
+		perror.HandleError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+	writeProgress(w, chi.URLParam(r, "courseRef"), student.UserName, student.UUID)
+}
+
+// issueOnCompletion issues the certificate of the course if the student just completed it.
+// A failure is only logged, as the progress is recorded anyway.
+func issueOnCompletion(courseID string, userName string) *certificate.Certificate {
+	c, err := enrollment.IssueCertificate(courseID, userName, hutils.Link)
+	if err != nil && !errors.Is(err, enrollment.ErrNotCompleted) && !errors.Is(err, course.ErrNotEnrolled) {
+		log.Printf("failed to issue the certificate of %s for %s: %v", courseID, userName, err)
+	}
+	return c
+}
+
+func writeProgress(w http.ResponseWriter, courseID string, userName string, userUUID string) {
+	records, err := course.ListProgress(userUUID, courseID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing progress", err)
+		return
+	}
+	summary, err := course.Summarize(courseID, records)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on summarizing progress", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(progressResponse{Course: courseID, UserName: userName, Summary: summary}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
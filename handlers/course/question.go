@@ -0,0 +1,117 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/pkg/assessment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Question is a question of the bank of a course, which quizzes draw from.
+type Question struct {
+	*assessment.Question
+}
+
+func (q *Question) Create(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewDecoder(r.Body).Decode(&q.Question); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	if err := course.AddQuestion(chi.URLParam(r, "courseRef"), q.Question); err != nil {
+		handleQuestionError(w, err)
+		return
+	}
+	writeQuestion(w, q.Question)
+}
+
+// List returns the questions of the bank, filtered by ?tag= & ?difficulty= if given.
+func (q *Question) List(w http.ResponseWriter, r *http.Request) {
+	questions, err := course.ListQuestions(chi.URLParam(r, "courseRef"), r.URL.Query().Get("tag"), r.URL.Query().Get("difficulty"))
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing questions", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(questions); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func (q *Question) Get(w http.ResponseWriter, r *http.Request) {
+	question, err := course.GetQuestion(chi.URLParam(r, "courseRef"), chi.URLParam(r, "id"))
+	if err != nil {
+		handleQuestionError(w, err)
+		return
+	}
+	writeQuestion(w, question)
+}
+
+// Update replaces the question. Quizzes already drawn keep it as it was.
+func (q *Question) Update(w http.ResponseWriter, r *http.Request) {
+	q.Question = &assessment.Question{}
+	if err := json.NewDecoder(r.Body).Decode(&q.Question); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	q.ID = chi.URLParam(r, "id")
+	if err := course.UpdateQuestion(chi.URLParam(r, "courseRef"), q.Question); err != nil {
+		handleQuestionError(w, err)
+		return
+	}
+	writeQuestion(w, q.Question)
+}
+
+func (q *Question) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := course.DeleteQuestion(chi.URLParam(r, "courseRef"), chi.URLParam(r, "id")); err != nil {
+		handleQuestionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeQuestion(w http.ResponseWriter, question *assessment.Question) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(question); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func handleQuestionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, course.ErrQuestionNotFound):
+		perror.HandleError(w, http.StatusNotFound, "", err)
+	case errors.Is(err, course.ErrInvalidQuestion):
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+	default:
+		perror.HandleError(w, http.StatusInternalServerError, "Error on updating question bank", err)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/pkg/assessment"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/progress"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type quizResponse struct {
+	*course.QuizDraw
+	Questions   []assessment.Question `json:"questions"` // without their answers
+	PassPercent float64               `json:"passPercent"`
+}
+
+type quizSubmitResponse struct {
+	quizResponse
+	Certificate *certificate.Certificate `json:"certificate,omitempty"` // issued as passing completed the course
+}
+
+// DrawQuiz returns the quiz drawn for the signed-in student, drawing it on the first call.
+func (c *Content) DrawQuiz(w http.ResponseWriter, r *http.Request) {
+	content, draw, ok := drawQuiz(w, r)
+	if !ok {
+		return
+	}
+	writeQuiz(w, newQuizResponse(content, draw))
+}
+
+// SubmitQuiz grades the answers of the signed-in student to the quiz drawn for them.
+// Passing completes the content, which may issue the certificate of the course.
+func (c *Content) SubmitQuiz(w http.ResponseWriter, r *http.Request) {
+	var req answersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	content, draw, ok := drawQuiz(w, r)
+	if !ok {
+		return
+	}
+	err := draw.Submit(content.Quiz, req.Answers, time.Now())
+	switch {
+	case errors.Is(err, course.ErrInvalidQuizAnswer):
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	case errors.Is(err, course.ErrNoQuizAttempts), errors.Is(err, course.ErrQuizConflict):
+		perror.HandleError(w, http.StatusConflict, "", err)
+		return
+	case err != nil:
+		perror.HandleError(w, http.StatusInternalServerError, "Error on submitting quiz", err)
+		return
+	}
+
+	response := quizSubmitResponse{quizResponse: newQuizResponse(content, draw)}
+	if draw.Score.Passed {
+		info, err := auth.GetUserInfoFromSession(r)
+		if err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+			return
+		}
+		if _, err := course.RecordProgress(info.UUID, content, progress.Event{Type: progress.Completed}); err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "Error on recording progress", err)
+			return
+		}
+		response.Certificate = issueOnCompletion(content.CourseRef, info.Name)
+	}
+	writeQuiz(w, response)
+}
+
+func drawQuiz(w http.ResponseWriter, r *http.Request) (*course.Content, *course.QuizDraw, bool) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return nil, nil, false
+	}
+	content, err := course.GetContent(chi.URLParam(r, "courseRef"), chi.URLParam(r, "id"))
+	if errors.Is(err, course.ErrContentNotFound) {
+		perror.HandleError(w, http.StatusNotFound, "", err)
+		return nil, nil, false
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting content", err)
+		return nil, nil, false
+	}
+	draw, err := course.DrawQuiz(info.UUID, content)
+	switch {
+	case errors.Is(err, course.ErrNotQuiz):
+		perror.HandleError(w, http.StatusNotFound, "", err)
+	case errors.Is(err, assessment.ErrNotEnoughQuestions):
+		perror.HandleError(w, http.StatusConflict, "", err)
+	case err != nil:
+		perror.HandleError(w, http.StatusInternalServerError, "Error on drawing quiz", err)
+	default:
+		return content, draw, true
+	}
+	return nil, nil, false
+}
+
+// newQuizResponse leaves out the results of each question until the quiz has no attempts left, for the student
+// not to find the answers out by submitting again.
+func newQuizResponse(content *course.Content, draw *course.QuizDraw) quizResponse {
+	shown := *draw
+	if !draw.Final(content.Quiz) {
+		shown.Score, shown.Best = withoutResults(draw.Score), withoutResults(draw.Best)
+	}
+	return quizResponse{QuizDraw: &shown, Questions: assessment.Redact(draw.Questions), PassPercent: content.Quiz.PassPercent}
+}
+
+func withoutResults(score *assessment.Score) *assessment.Score {
+	if score == nil {
+		return nil
+	}
+	total := *score
+	total.Questions = nil
+	return &total
+}
+
+func writeQuiz(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// newQuizSeed returns the secret seed of a new quiz, for students not to predict each other's draws.
+func newQuizSeed() (string, error) {
+	seed := make([]byte, 16)
+	if _, err := rand.Read(seed); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(seed), nil
+}
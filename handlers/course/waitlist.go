@@ -0,0 +1,125 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/waitlist"
+	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type waitlistResponse struct {
+	*waitlist.Entry
+	Position int64 `json:"position"`
+}
+
+// JoinWaitlist queues the signed-in user for a seat of the full course.
+func (c *Course) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	entry, position, err := enrollment.JoinWaitlist(chi.URLParam(r, "id"), info.Name, time.Now())
+	if err != nil {
+		handleEnrollmentError(w, err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.WaitlistJoin,
+		Actor:     info.Name,
+		Subject:   info.Name,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"course": entry.Course},
+	})
+	writeWaitlistEntry(w, entry, position)
+}
+
+// WaitlistStatus tells the signed-in user their place in the waitlist, or until when their seat is reserved.
+func (c *Course) WaitlistStatus(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	entry, position, err := enrollment.WaitlistStatus(chi.URLParam(r, "id"), info.Name)
+	if err != nil {
+		handleEnrollmentError(w, err)
+		return
+	}
+	writeWaitlistEntry(w, entry, position)
+}
+
+// LeaveWaitlist takes the signed-in user off the waitlist.
+func (c *Course) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	courseID := chi.URLParam(r, "id")
+	if err := enrollment.LeaveWaitlist(courseID, info.Name); err != nil {
+		handleEnrollmentError(w, err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.WaitlistLeave,
+		Actor:     info.Name,
+		Subject:   info.Name,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"course": courseID},
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListWaitlist returns the waitlist of the course in order, for its staff.
+func (c *Course) ListWaitlist(w http.ResponseWriter, r *http.Request) {
+	entries, err := waitlist.List(chi.URLParam(r, "id"))
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on listing waitlist", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func writeWaitlistEntry(w http.ResponseWriter, entry *waitlist.Entry, position int64) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(waitlistResponse{Entry: entry, Position: position}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Session struct{}
+
+func (s Session) List(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	sessions, err := auth.ListSessions(r, info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing sessions", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sessions); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func (s Session) Revoke(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	if err := auth.RevokeSession(info.UUID, chi.URLParam(r, "id")); err != nil {
+		perror.HandleError(w, http.StatusNotFound, "Error on revoking session", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s Session) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	if err := auth.RevokeAllSessions(info.UUID); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on revoking sessions", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// CSRF returns the CSRF token of the current session, for clients which lost the one issued at sign in.
+func (s Session) CSRF(w http.ResponseWriter, r *http.Request) {
+	csrf, err := auth.GetCSRFToken(w, r)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting CSRF token", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"csrfToken": csrf}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package token
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/praromvik/praromvik/models/token"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Token struct {
+	*token.Token
+}
+
+type createResponse struct {
+	*token.Token
+	Secret string `json:"token"`
+}
+
+func (t *Token) Create(w http.ResponseWriter, r *http.Request) {
+	tok := &token.Token{}
+	if err := json.NewDecoder(r.Body).Decode(tok); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	errCode, err := tok.ValidateForm()
+	if err != nil {
+		perror.HandleError(w, errCode, "", err)
+		return
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	tok.UserName, tok.UserUUID = info.Name, info.UUID
+
+	plain, err := tok.Generate()
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+	if err := tok.AddTokenToDB(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "failed to add token into database", err)
+		return
+	}
+
+	// The plaintext is only ever shown in this response.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(createResponse{Token: tok, Secret: plain}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func (t *Token) List(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	tokens, err := token.List(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting token list", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func (t *Token) Revoke(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	tok := &token.Token{ID: chi.URLParam(r, "id"), UserUUID: info.UUID}
+	if err := tok.Revoke(); err != nil {
+		perror.HandleError(w, http.StatusNotFound, "Error on revoking token", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,257 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/exam"
+	"github.com/praromvik/praromvik/models/token"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/waitlist"
+	"github.com/praromvik/praromvik/pkg/account"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+)
+
+// archive is everything we hold about a user, as handed out by the data export.
+type archive struct {
+	ExportedAt   time.Time                 `json:"exportedAt"`
+	Profile      map[string]interface{}    `json:"profile"`
+	Courses      []membership              `json:"courses"`
+	Waitlists    []waitlist.Entry          `json:"waitlists"`
+	Progress     []course.Progress         `json:"progress"`
+	Certificates []certificate.Certificate `json:"certificates"`
+	Attempts     []exam.Attempt            `json:"examAttempts"`
+	Quizzes      []course.QuizDraw         `json:"quizzes"`
+	Tokens       []token.Token             `json:"tokens"`
+	AuditEvents  []audit.Event             `json:"auditEvents"`
+}
+
+type membership struct {
+	CourseID string `json:"courseId"`
+	Title    string `json:"title"`
+	As       string `json:"as"` // student, instructor or moderator
+}
+
+type deletionRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	Reason          string `json:"reason"`
+}
+
+// Export hands the signed-in user a JSON archive of their personal data.
+func (u User) Export(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	u.User = &user.User{UserName: info.Name}
+	if err := u.GetFromMongo(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting user", err)
+		return
+	}
+	profile, err := hutils.Project(u.User, hutils.Public, hutils.Self)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	courses, err := course.ListByMember(info.Name)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing courses", err)
+		return
+	}
+	waitlists, err := waitlist.ListByUser(info.Name)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing waitlists", err)
+		return
+	}
+	progress, err := course.ListProgress(info.UUID, "")
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing progress", err)
+		return
+	}
+	certificates, err := certificate.ListByUser(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing certificates", err)
+		return
+	}
+	attempts, err := exam.ListAttemptsByUser(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing exam attempts", err)
+		return
+	}
+	quizzes, err := course.ListQuizDraws(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing quizzes", err)
+		return
+	}
+	tokens, err := token.List(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing tokens", err)
+		return
+	}
+	events, err := audit.ListAbout(info.Name)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing audit events", err)
+		return
+	}
+
+	data := archive{
+		ExportedAt:   time.Now().UTC(),
+		Profile:      profile,
+		Courses:      memberships(courses, info.Name),
+		Waitlists:    waitlists,
+		Progress:     progress,
+		Certificates: certificates,
+		Attempts:     attempts,
+		Quizzes:      quizzes,
+		Tokens:       tokens,
+		AuditEvents:  events,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="praromvik-%s.json"`, info.Name))
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// RequestDeletion schedules the account of the signed-in user for deletion after the grace period.
+func (u User) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	var req deletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	if !reauthenticate(w, r, info.Name, req.CurrentPassword) {
+		return
+	}
+	u.User = &user.User{UserName: info.Name}
+	deletion, err := u.User.RequestDeletion(req.Reason)
+	if err != nil {
+		perror.HandleError(w, http.StatusConflict, "", err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.AccountDeletionRequest,
+		Actor:     info.Name,
+		Subject:   info.Name,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"scheduledAt": deletion.ScheduledAt.Format(time.RFC3339)},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(deletion); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// CancelDeletion withdraws the pending deletion of the signed-in user.
+func (u User) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	u.User = &user.User{UserName: info.Name}
+	if err := u.User.CancelDeletion(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.AccountDeletionCancel,
+		Actor:     info.Name,
+		Subject:   info.Name,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// PendingDeletions lists the accounts waiting to be deleted (admin only).
+func (u User) PendingDeletions(w http.ResponseWriter, r *http.Request) {
+	users, err := user.PendingDeletions(false)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on listing pending deletions", err)
+		return
+	}
+	data, err := hutils.ProjectList(users, func(user.User) []hutils.Relation {
+		return []hutils.Relation{hutils.Public, hutils.Admin}
+	})
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// PurgeDeletions purges the accounts whose grace period is over right away, instead of waiting for the sweeper.
+func (u User) PurgeDeletions(w http.ResponseWriter, r *http.Request) {
+	purged, err := account.PurgeDue()
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, fmt.Sprintf("Purged %d accounts before failing", purged), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int{"purged": purged}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func memberships(courses []course.Course, userName string) []membership {
+	result := make([]membership, 0, len(courses))
+	for _, c := range courses {
+		if slices.Contains(c.Students, userName) {
+			result = append(result, membership{CourseID: c.CourseId, Title: c.Title, As: "student"})
+		}
+		if slices.Contains(c.Instructors, userName) {
+			result = append(result, membership{CourseID: c.CourseId, Title: c.Title, As: "instructor"})
+		}
+		if slices.Contains(c.Moderators, userName) {
+			result = append(result, membership{CourseID: c.CourseId, Title: c.Title, As: "moderator"})
+		}
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type moderationRequest struct {
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+type directoryPage struct {
+	Users    []map[string]interface{} `json:"users"`
+	Total    int64                    `json:"total"`
+	Page     int64                    `json:"page"`
+	PageSize int64                    `json:"pageSize"`
+}
+
+// Directory lists users page by page, filtered by name, email, phone, role & status.
+func (u User) Directory(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := user.Query{
+		Name:   params.Get("name"),
+		Email:  params.Get("email"),
+		Phone:  params.Get("phone"),
+		Role:   params.Get("role"),
+		Status: params.Get("status"),
+	}
+	if q.Role != "" && !user.ValidRole(q.Role) {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("unknown role '%s'", q.Role))
+		return
+	}
+	switch q.Status {
+	case "", user.StatusActive, user.StatusSuspended, user.StatusBanned:
+	default:
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("unknown status '%s'", q.Status))
+		return
+	}
+	var err error
+	if q.Page, err = intParam(params.Get("page")); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	if q.PageSize, err = intParam(params.Get("pageSize")); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Invalid pageSize", err)
+		return
+	}
+
+	page, err := user.Search(q)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on searching users", err)
+		return
+	}
+	relations := []hutils.Relation{hutils.Public}
+	roles, err := auth.GetSessionRoles(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	if slices.Contains(roles, utils.Admin) {
+		relations = append(relations, hutils.Admin)
+	}
+	users, err := hutils.ProjectList(page.Users, func(user.User) []hutils.Relation { return relations })
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(directoryPage{Users: users, Total: page.Total, Page: page.Page, PageSize: page.PageSize}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// Suspend keeps the user out until the given time, or until reactivated.
+func (u User) Suspend(w http.ResponseWriter, r *http.Request) {
+	u.moderate(w, r, audit.UserSuspend, func(target *user.User, req moderationRequest) error {
+		if req.Until != nil && !req.Until.After(time.Now()) {
+			return fmt.Errorf("the suspension has to end in the future")
+		}
+		return target.SetStatus(user.StatusSuspended, req.Reason, req.Until)
+	})
+}
+
+// Ban keeps the user out for good, until reactivated.
+func (u User) Ban(w http.ResponseWriter, r *http.Request) {
+	u.moderate(w, r, audit.UserBan, func(target *user.User, req moderationRequest) error {
+		return target.SetStatus(user.StatusBanned, req.Reason, nil)
+	})
+}
+
+// Reactivate lifts a suspension or ban.
+func (u User) Reactivate(w http.ResponseWriter, r *http.Request) {
+	u.moderate(w, r, audit.UserReactivate, func(target *user.User, req moderationRequest) error {
+		return target.SetStatus(user.StatusActive, req.Reason, nil)
+	})
+}
+
+// ForcePasswordReset signs the user out everywhere, and makes them change their password on the next sign in.
+func (u User) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	u.moderate(w, r, audit.UserForcePasswordReset, func(target *user.User, req moderationRequest) error {
+		return target.RequirePasswordReset(true)
+	})
+}
+
+// moderate applies the action to the user in the URL, syncs the restrictions every request is checked against,
+// and signs them out if they are restricted.
+func (u User) moderate(w http.ResponseWriter, r *http.Request, eventType string, action func(*user.User, moderationRequest) error) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("a reason is required for every moderation action"))
+		return
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+
+	u.User = &user.User{UserName: chi.URLParam(r, "userName")}
+	if u.UserName == info.Name {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("you can't moderate yourself"))
+		return
+	}
+	if err := u.FetchAuthDataFromDB(); err != nil {
+		perror.HandleError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+	if err := action(u.User, req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on moderating user", err)
+		return
+	}
+	if err := syncRestrictions(u.User); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on syncing account restrictions", err)
+		return
+	}
+	if u.EffectiveStatus(time.Now()) != user.StatusActive || u.MustResetPassword {
+		if err := auth.RevokeAllSessions(u.UUID); err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "Error on revoking sessions", err)
+			return
+		}
+	}
+
+	details := map[string]string{"reason": req.Reason}
+	if u.SuspendedUntil != nil {
+		details["until"] = u.SuspendedUntil.Format(time.RFC3339)
+	}
+	audit.Log(&audit.Event{
+		Type:      eventType,
+		Actor:     info.Name,
+		Subject:   u.UserName,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   details,
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// syncRestrictions mirrors the moderation state of the user for the per request checks.
+func syncRestrictions(u *user.User) error {
+	if err := auth.SetUserStatus(u.UUID, u.Status, u.SuspendedUntil); err != nil {
+		return err
+	}
+	return auth.RequirePasswordReset(u.UUID, u.MustResetPassword)
+}
+
+func intParam(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,125 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type impersonationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Impersonate lets an admin act as the user for a limited time, e.g. to reproduce what a student sees.
+func (u User) Impersonate(w http.ResponseWriter, r *http.Request) {
+	var req impersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("a reason is required to impersonate a user"))
+		return
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+
+	u.User = &user.User{UserName: chi.URLParam(r, "userName")}
+	if u.UserName == info.Name {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("you can't impersonate yourself"))
+		return
+	}
+	if err := u.FetchAuthDataFromDB(); err != nil {
+		perror.HandleError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+	if _, admin := u.ActiveRoles(time.Now())[string(utils.Admin)]; admin {
+		perror.HandleError(w, http.StatusForbidden, "", fmt.Errorf("admins can't be impersonated"))
+		return
+	}
+
+	imp, err := auth.StartImpersonation(w, r, u.User, req.Reason)
+	if errors.Is(err, auth.ErrAlreadyImpersonating) {
+		perror.HandleError(w, http.StatusConflict, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on starting impersonation", err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.ImpersonationStart,
+		Actor:     imp.Admin,
+		Subject:   imp.Target,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"reason": imp.Reason, "expiresAt": imp.ExpiresAt.Format(time.RFC3339)},
+	})
+
+	w.Header().Set(auth.ImpersonationHeader, imp.Target)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(imp); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// StopImpersonation returns the session to the admin.
+func (u User) StopImpersonation(w http.ResponseWriter, r *http.Request) {
+	imp, err := auth.StopImpersonation(w, r)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on stopping impersonation", err)
+		return
+	}
+	if imp == nil {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("the session isn't impersonating anyone"))
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.ImpersonationStop,
+		Actor:     imp.Admin,
+		Subject:   imp.Target,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"reason": "stopped"},
+	})
+	w.Header().Del(auth.ImpersonationHeader)
+	w.WriteHeader(http.StatusOK)
+}
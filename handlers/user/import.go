@@ -0,0 +1,149 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/pkg/account"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/roster"
+)
+
+// maxRosterSize bounds the CSV body of an import.
+const maxRosterSize = 5 << 20
+
+type invitationAcceptance struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Import creates the accounts of the users listed in the CSV body, and reports what happened to every row.
+// The course query parameter enrolls them, invite=false skips mailing the invitations
+// & dryRun=true only validates the rows.
+func (u User) Import(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	params := r.URL.Query()
+	opts := account.ImportOptions{InvitedBy: info.Name, CourseID: params.Get("course"), Invite: true, Link: hutils.Link}
+	if value := params.Get("invite"); value != "" {
+		if opts.Invite, err = strconv.ParseBool(value); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Invalid invite", err)
+			return
+		}
+	}
+	if value := params.Get("dryRun"); value != "" {
+		if opts.DryRun, err = strconv.ParseBool(value); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Invalid dryRun", err)
+			return
+		}
+	}
+
+	rows, err := roster.Parse(http.MaxBytesReader(w, r.Body, maxRosterSize))
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing CSV", err)
+		return
+	}
+	report, err := account.Import(rows, opts)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on importing users", err)
+		return
+	}
+	if !opts.DryRun {
+		audit.Log(&audit.Event{
+			Type:      audit.UserImport,
+			Actor:     info.Name,
+			IP:        auth.ClientIP(r),
+			UserAgent: r.UserAgent(),
+			Details: map[string]string{
+				"course":  opts.CourseID,
+				"created": strconv.Itoa(report.Created),
+				"failed":  strconv.Itoa(report.Failed),
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// GetInvitation tells whom the invitation token is for, so that they can choose their password.
+func (u User) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	invited, err := user.GetInvitation(r.URL.Query().Get("token"))
+	if errors.Is(err, user.ErrInvalidInvitation) {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting invitation", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		UserName  string    `json:"userName"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}{invited.UserName, invited.Invitation.ExpiresAt}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// AcceptInvitation sets the password of an invited user. They sign in as usual afterwards.
+func (u User) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req invitationAcceptance
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	invited, err := user.AcceptInvitation(req.Token, req.Password)
+	if errors.Is(err, user.ErrInvalidInvitation) {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on accepting invitation", err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.InvitationAccept,
+		Actor:     invited.UserName,
+		Subject:   invited.UserName,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	w.WriteHeader(http.StatusOK)
+}
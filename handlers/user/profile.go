@@ -0,0 +1,326 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/asset"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/token"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/waitlist"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/mailer"
+)
+
+// profilePatch is the body of PATCH /api/user/me. Only the fields present are changed.
+// Changing the email, phone, password or user name requires the current password.
+type profilePatch struct {
+	user.ProfileUpdate
+	Email           *string `json:"email"`
+	Phone           *string `json:"phone"`
+	Password        *string `json:"password"`
+	UserName        *string `json:"userName"`
+	CurrentPassword string  `json:"currentPassword"`
+}
+
+func (p profilePatch) sensitive() bool {
+	return p.Email != nil || p.Phone != nil || p.Password != nil || p.UserName != nil
+}
+
+// UpdateMe changes the profile of the signed-in user.
+func (u User) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var patch profilePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return
+	}
+	if err := patch.ProfileUpdate.Validate(); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	u.User = &user.User{UserName: info.Name, UUID: info.UUID}
+	// Nothing is written before every change is known to apply.
+	if err := u.checkPatch(patch); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if patch.sensitive() && !reauthenticate(w, r, info.Name, patch.CurrentPassword) {
+		return
+	}
+
+	if err := u.UpdateProfile(patch.ProfileUpdate); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on updating profile", err)
+		return
+	}
+	var changed []string
+	if patch.Phone != nil {
+		if err := u.SetPhone(*patch.Phone); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Error on changing phone", err)
+			return
+		}
+		changed = append(changed, "phone")
+	}
+	if patch.Password != nil {
+		if err := u.SetPassword(*patch.Password); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Error on changing password", err)
+			return
+		}
+		if err := auth.RevokeOtherSessions(r, info.UUID); err != nil {
+			log.Printf("failed to sign %s out of other sessions: %v", info.Name, err)
+		}
+		// A new password satisfies a forced reset.
+		if err := u.RequirePasswordReset(false); err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "Error on clearing the password reset", err)
+			return
+		}
+		if err := auth.RequirePasswordReset(info.UUID, false); err != nil {
+			log.Printf("failed to clear the password reset of %s: %v", info.Name, err)
+		}
+		changed = append(changed, "password")
+	}
+	if patch.Email != nil {
+		if err := u.requestEmailChange(*patch.Email); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Error on changing email", err)
+			return
+		}
+		changed = append(changed, "email")
+	}
+	if patch.UserName != nil && *patch.UserName != info.Name {
+		if err := u.changeUserName(w, r, *patch.UserName); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "Error on changing user name", err)
+			return
+		}
+		changed = append(changed, "userName")
+	}
+	if len(changed) > 0 {
+		audit.Log(&audit.Event{
+			Type:      audit.ProfileChange,
+			Actor:     u.UserName,
+			Subject:   u.UserName,
+			IP:        auth.ClientIP(r),
+			UserAgent: r.UserAgent(),
+			Details:   map[string]string{"fields": strings.Join(changed, ",")},
+		})
+	}
+
+	if err := u.GetFromMongo(); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting user", err)
+		return
+	}
+	data, err := hutils.Project(u.User, hutils.Public, hutils.Self)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+// checkPatch returns why a change of the patch can't apply, if one can't.
+func (u User) checkPatch(patch profilePatch) error {
+	if patch.Phone != nil {
+		if err := u.CheckPhone(*patch.Phone); err != nil {
+			return err
+		}
+	}
+	if patch.Password != nil {
+		if err := u.CheckNewPassword(*patch.Password); err != nil {
+			return err
+		}
+	}
+	if patch.Email != nil {
+		if err := u.CheckEmail(*patch.Email); err != nil {
+			return err
+		}
+	}
+	if patch.UserName != nil && *patch.UserName != u.UserName {
+		if err := u.CheckUserName(*patch.UserName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reauthenticate checks the current password of the signed-in user like a sign in does, under the same lockout,
+// so that a hijacked session can't guess it.
+func reauthenticate(w http.ResponseWriter, r *http.Request, userName string, currentPassword string) bool {
+	retryAfter, err := auth.SignInRetryAfter(userName, r)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "failed to check sign in lockout", err)
+		return false
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		perror.HandleError(w, http.StatusTooManyRequests, "too many failed attempts, try again later", nil)
+		return false
+	}
+	check := &user.User{UserName: userName, Password: currentPassword}
+	if valid, err := check.VerifyLoginData(); err != nil || !valid {
+		if err := auth.RecordSignInFailure(userName, r); err != nil {
+			log.Printf("failed to record sign in failure: %v", err)
+		}
+		perror.HandleError(w, http.StatusUnauthorized, "the current password is required for this change", err)
+		return false
+	}
+	if err := auth.ResetSignInFailures(userName); err != nil {
+		log.Printf("failed to reset sign in failures: %v", err)
+	}
+	return true
+}
+
+// requestEmailChange mails a confirmation link to the new email. The email only changes once it is confirmed.
+func (u User) requestEmailChange(email string) error {
+	secret, err := u.RequestEmailChange(email)
+	if err != nil {
+		return err
+	}
+	link := hutils.Link("/api/user/email/confirm?token=" + url.QueryEscape(secret))
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirm your new Praromvik email",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link within a day to use this address for your Praromvik account:\n%s\n\n"+
+			"If you didn't ask for it, ignore this mail.\n", u.UserName, link),
+	})
+}
+
+// changeUserName renames the signed-in user everywhere their name is kept.
+func (u User) changeUserName(w http.ResponseWriter, r *http.Request, newName string) error {
+	oldName := u.UserName
+	if err := u.ChangeUserName(newName); err != nil {
+		return err
+	}
+	if err := course.ReplaceMember(oldName, newName); err != nil {
+		return err
+	}
+	if err := token.RenameUser(u.UUID, newName); err != nil {
+		return err
+	}
+	if err := waitlist.RenameUser(oldName, newName); err != nil {
+		return err
+	}
+	// Other sessions still carry the former name.
+	if err := auth.RevokeOtherSessions(r, u.UUID); err != nil {
+		return err
+	}
+	return auth.SetSessionUserName(w, r, newName)
+}
+
+// ConfirmEmail applies an email change through the link mailed to the new address.
+func (u User) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	changed, err := user.ConfirmEmailChange(r.URL.Query().Get("token"))
+	if errors.Is(err, user.ErrInvalidEmailToken) {
+		perror.HandleError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on confirming email", err)
+		return
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.ProfileChange,
+		Actor:     changed.UserName,
+		Subject:   changed.UserName,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   map[string]string{"fields": "email", "confirmed": "true"},
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// UploadAvatar replaces the avatar of the signed-in user with the image in the "avatar" form field.
+func (u User) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, asset.MaxAvatarSize+1<<10)
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, fmt.Sprintf("expected an image of at most %d bytes in the 'avatar' field", asset.MaxAvatarSize), err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, asset.MaxAvatarSize+1))
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on reading avatar", err)
+		return
+	}
+	if len(data) > asset.MaxAvatarSize {
+		perror.HandleError(w, http.StatusRequestEntityTooLarge, "", fmt.Errorf("the avatar must be at most %d bytes", asset.MaxAvatarSize))
+		return
+	}
+	avatar, err := asset.NewImage(asset.KindAvatar, info.UUID, data)
+	if err != nil {
+		perror.HandleError(w, http.StatusUnsupportedMediaType, "", err)
+		return
+	}
+	if err := avatar.AddAssetToDB(); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on storing avatar", err)
+		return
+	}
+
+	u.User = &user.User{UserName: info.Name, UUID: info.UUID}
+	if err := u.GetFromMongo(); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on getting user", err)
+		return
+	}
+	previous := u.Avatar
+	if err := u.SetAvatar(avatar.ID); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on setting avatar", err)
+		return
+	}
+	if previous != "" {
+		if err := asset.Delete(previous); err != nil {
+			log.Printf("failed to delete the previous avatar %s: %v", previous, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"avatar": avatar.ID, "url": "/api/assets/" + avatar.ID}); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// roleChange is the request to grant or revoke a role. Every change needs a reason.
+type roleChange struct {
+	UserName  string     `json:"userName"`
+	Role      string     `json:"role"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type roleHolder struct {
+	UserName string           `json:"userName"`
+	Role     string           `json:"role"`
+	Roles    []user.RoleGrant `json:"roles"`
+}
+
+func (u User) GrantRole(w http.ResponseWriter, r *http.Request) {
+	change, actor, ok := decodeRoleChange(w, r)
+	if !ok {
+		return
+	}
+	if change.ExpiresAt != nil && !change.ExpiresAt.After(time.Now()) {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("expiresAt must be in the future"))
+		return
+	}
+
+	u.User = &user.User{UserName: change.UserName}
+	grant := user.RoleGrant{
+		Role:      change.Role,
+		Reason:    change.Reason,
+		GrantedBy: actor,
+		GrantedAt: time.Now().UTC(),
+		ExpiresAt: change.ExpiresAt,
+	}
+	if err := u.User.GrantRole(grant); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on granting role", err)
+		return
+	}
+	details := map[string]string{"role": change.Role, "reason": change.Reason}
+	if change.ExpiresAt != nil {
+		details["expiresAt"] = change.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	u.afterRoleChange(w, r, audit.RoleGrant, actor, details)
+}
+
+func (u User) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	change, actor, ok := decodeRoleChange(w, r)
+	if !ok {
+		return
+	}
+	u.User = &user.User{UserName: change.UserName}
+	if err := u.User.RevokeRole(change.Role); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on revoking role", err)
+		return
+	}
+	u.afterRoleChange(w, r, audit.RoleRevoke, actor, map[string]string{"role": change.Role, "reason": change.Reason})
+}
+
+// SetBaseRole replaces the base role of the user, the one held without a grant, to promote or demote them.
+func (u User) SetBaseRole(w http.ResponseWriter, r *http.Request) {
+	change, actor, ok := decodeRoleChange(w, r)
+	if !ok {
+		return
+	}
+	if change.ExpiresAt != nil {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("the base role doesn't expire, grant the role instead"))
+		return
+	}
+	if change.UserName == actor {
+		// Kept from demoting themselves, so that some admin always remains.
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("admins can't change their own base role"))
+		return
+	}
+	u.User = &user.User{UserName: change.UserName}
+	previous, err := u.User.SetBaseRole(change.Role)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on setting base role", err)
+		return
+	}
+	u.afterRoleChange(w, r, audit.RoleSetBase, actor, map[string]string{"role": change.Role, "previous": previous, "reason": change.Reason})
+}
+
+func (u User) ListByRole(w http.ResponseWriter, r *http.Request) {
+	role := chi.URLParam(r, "role")
+	if !user.ValidRole(role) {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("unknown role '%s'", role))
+		return
+	}
+	users, err := user.ListByRole(role)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on listing users", err)
+		return
+	}
+	holders := make([]roleHolder, 0, len(users))
+	for _, holder := range users {
+		holders = append(holders, roleHolder{UserName: holder.UserName, Role: holder.Role, Roles: holder.Roles})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(holders); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on encoding JSON response", err)
+	}
+}
+
+func decodeRoleChange(w http.ResponseWriter, r *http.Request) (*roleChange, string, bool) {
+	var change roleChange
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+		return nil, "", false
+	}
+	if change.UserName == "" || !user.ValidRole(change.Role) {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("a userName & a valid role are required"))
+		return nil, "", false
+	}
+	if strings.TrimSpace(change.Reason) == "" {
+		perror.HandleError(w, http.StatusBadRequest, "", fmt.Errorf("a reason is required for every role change"))
+		return nil, "", false
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
+		return nil, "", false
+	}
+	return &change, info.Name, true
+}
+
+func (u User) afterRoleChange(w http.ResponseWriter, r *http.Request, eventType string, actor string, details map[string]string) {
+	audit.Log(&audit.Event{
+		Type:      eventType,
+		Actor:     actor,
+		Subject:   u.UserName,
+		UserAgent: r.UserAgent(),
+		Details:   details,
+	})
+	// Sessions carry the roles they were signed in with, so sign the user out everywhere.
+	if err := auth.RevokeAllSessions(u.UUID); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Failed to revoke user sessions", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
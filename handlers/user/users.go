@@ -26,9 +26,15 @@ package user
 
 import (
 	"encoding/json"
+	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/audit"
 	"github.com/praromvik/praromvik/models/user"
 	mutils "github.com/praromvik/praromvik/models/utils"
 	"github.com/praromvik/praromvik/pkg/auth"
@@ -44,12 +50,42 @@ type User struct {
 	*user.User
 }
 
+// signUpRequest is what a user chooses when signing up. Their roles, status, courses & the rest are only
+// ever set by the server, so they aren't decoded from the request.
+type signUpRequest struct {
+	UserName string `json:"userName"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Phone    string `json:"phone"`
+	user.ProfileUpdate
+}
+
+func (req signUpRequest) newUser() *user.User {
+	u := &user.User{UserName: req.UserName, Email: req.Email, Password: req.Password, Phone: req.Phone}
+	if req.DisplayName != nil {
+		u.DisplayName = *req.DisplayName
+	}
+	if req.Bio != nil {
+		u.Bio = *req.Bio
+	}
+	if req.SocialLinks != nil {
+		u.SocialLinks = *req.SocialLinks
+	}
+	return u
+}
+
 func (u User) SignUp(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
-		if err := json.NewDecoder(r.Body).Decode(&u.User); err != nil {
+		var req signUpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
 			return
 		}
+		if err := req.ProfileUpdate.Validate(); err != nil {
+			perror.HandleError(w, http.StatusBadRequest, "", err)
+			return
+		}
+		u.User = req.newUser()
 		errCode, err := u.User.ValidateForm()
 		if err != nil {
 			perror.HandleError(w, errCode, "", err)
@@ -59,6 +95,7 @@ func (u User) SignUp(w http.ResponseWriter, r *http.Request) {
 		u.UUID = uuid.NewString()
 		if err := u.HashPassword(); err != nil {
 			perror.HandleError(w, http.StatusBadRequest, "Failed to hash password", err)
+			return
 		}
 		if u.Email == mutils.AdminEmail {
 			u.Role = string(mutils.Admin)
@@ -68,6 +105,7 @@ func (u User) SignUp(w http.ResponseWriter, r *http.Request) {
 
 		if err := u.User.AddUserDataToDB(); err != nil {
 			perror.HandleError(w, http.StatusBadRequest, "failed to add form data into database", err)
+			return
 		}
 		w.WriteHeader(http.StatusOK)
 	} else {
@@ -81,13 +119,39 @@ func (u User) SignIn(w http.ResponseWriter, r *http.Request) {
 			perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
 			return
 		}
+		retryAfter, err := auth.SignInRetryAfter(u.UserName, r)
+		if err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "failed to check sign in lockout", err)
+			return
+		}
+		if retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			perror.HandleError(w, http.StatusTooManyRequests, "too many failed sign in attempts, try again later", nil)
+			return
+		}
+
 		valid, err := u.User.VerifyLoginData()
 		if err != nil && status.Code(err) != codes.NotFound {
 			perror.HandleError(w, http.StatusUnauthorized, "failed to login", err)
 			return
 		}
 		if !valid {
+			if err := auth.RecordSignInFailure(u.UserName, r); err != nil {
+				log.Printf("failed to record sign in failure: %v", err)
+			}
 			perror.HandleError(w, http.StatusUnauthorized, "invalid username or password", nil)
+			return
+		}
+		if err := auth.ResetSignInFailures(u.UserName); err != nil {
+			log.Printf("failed to reset sign in failures: %v", err)
+		}
+		if accountStatus := u.User.EffectiveStatus(time.Now()); accountStatus != user.StatusActive {
+			perror.HandleError(w, http.StatusForbidden, "This account is "+accountStatus, nil)
+			return
+		}
+		if err := syncRestrictions(u.User); err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "failed to sync account restrictions", err)
+			return
 		}
 		if err := auth.StoreAuthenticated(w, r, u.User, true); err != nil {
 			perror.HandleError(w, http.StatusInternalServerError, "failed to store session token", err)
@@ -109,7 +173,7 @@ func (u User) SignIn(w http.ResponseWriter, r *http.Request) {
 }
 
 func (u User) SignOut(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodPost {
+	if r.Method == http.MethodDelete {
 		if err := auth.StoreAuthenticated(w, r, u.User, false); err != nil {
 			perror.HandleError(w, http.StatusInternalServerError, "failed to store session token", err)
 			return
@@ -119,21 +183,23 @@ func (u User) SignOut(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (u User) ProvideRoleToUser(w http.ResponseWriter, r *http.Request) {
-	if err := json.NewDecoder(r.Body).Decode(&u.User); err != nil {
-		perror.HandleError(w, http.StatusBadRequest, "Error on parsing JSON", err)
+func (u User) Unlock(w http.ResponseWriter, r *http.Request) {
+	userName := chi.URLParam(r, "userName")
+	if err := auth.UnlockSignIn(userName); err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Error on unlocking user", err)
 		return
 	}
-
-	if err := u.User.FetchAndSetUUIDFromDB(); err != nil {
-		perror.HandleError(w, http.StatusBadRequest, "", err)
-		return
-	}
-
-	if err := u.UpdateUserDataToDB(); err != nil {
-		perror.HandleError(w, http.StatusBadRequest, "Error on update user", err)
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusBadRequest, "Error on getting session", err)
 		return
 	}
+	audit.Log(&audit.Event{
+		Type:      audit.SignInUnlock,
+		Actor:     info.Name,
+		Subject:   userName,
+		UserAgent: r.UserAgent(),
+	})
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -141,10 +207,20 @@ func (u User) Get(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		u.UserName = chi.URLParam(r, "userName")
 		if err := u.GetFromMongo(); err != nil {
+			// A renamed user is still found by their former names.
+			if current, rerr := user.ResolvePreviousUserName(u.UserName); rerr == nil {
+				http.Redirect(w, r, "/api/user/"+url.PathEscape(current), http.StatusMovedPermanently)
+				return
+			}
 			perror.HandleError(w, http.StatusBadRequest, "Error on getting user", err)
 			return
 		}
-		data, err := hutils.RemovedUUID(u)
+		relations, err := relationsTo(r, u.UserName)
+		if err != nil {
+			perror.HandleError(w, http.StatusInternalServerError, "", err)
+			return
+		}
+		data, err := hutils.Project(u.User, relations...)
 		if err != nil {
 			perror.HandleError(w, http.StatusInternalServerError, "", err)
 			return
@@ -0,0 +1,52 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSignUpRequestKeepsAccountState(t *testing.T) {
+	body := `{
+		"userName": "student-1", "email": "student@praromvik.com", "password": "secret", "phone": "+8801540179777",
+		"displayName": "Student", "role": "admin", "roles": [{"role": "admin"}], "status": "active",
+		"suspendedUntil": "2024-01-01T00:00:00Z", "mustResetPassword": true, "invitation": {"tokenHash": "x"},
+		"deletion": {"requestedAt": "2024-01-01T00:00:00Z"}, "certificates": ["7XQ2-M9KD-3HVA-P0TE"],
+		"enrolledCourses": [{"name": "advanced-golang"}], "uuid": "chosen"
+	}`
+	var req signUpRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	u := req.newUser()
+	if u.UserName != "student-1" || u.Email != "student@praromvik.com" || u.Password != "secret" || u.DisplayName != "Student" {
+		t.Fatalf("expected the chosen fields to be kept, got %+v", u)
+	}
+	if u.Role != "" || u.Roles != nil || u.Status != "" || u.SuspendedUntil != nil || u.MustResetPassword ||
+		u.Invitation != nil || u.Deletion != nil || u.Certificates != nil || u.EnrolledCourses != nil || u.UUID != "" {
+		t.Fatalf("expected the account state to be left to the server, got %+v", u)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"net/http"
+	"slices"
+
+	hutils "github.com/praromvik/praromvik/handlers/utils"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/auth"
+)
+
+// relationsTo returns how the signed-in user relates to the given user.
+func relationsTo(r *http.Request, userName string) ([]hutils.Relation, error) {
+	relations := []hutils.Relation{hutils.Public}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := auth.GetSessionRoles(r)
+	if err != nil {
+		return nil, err
+	}
+	if info.Name == userName {
+		relations = append(relations, hutils.Self)
+	}
+	if slices.Contains(roles, utils.Admin) {
+		relations = append(relations, hutils.Admin)
+	}
+	staff, err := course.IsStaffOfStudent(info.Name, userName)
+	if err != nil {
+		return nil, err
+	}
+	if staff {
+		relations = append(relations, hutils.Instructor)
+	}
+	return relations, nil
+}
@@ -26,9 +26,35 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 )
 
-func RemovedUUID(v interface{}) (map[string]interface{}, error) {
+// Relation is how the viewer of a response relates to the data in it.
+// A viewer can hold more than one relation at once.
+type Relation string
+
+const (
+	Public     Relation = "public"
+	Self       Relation = "self"
+	Admin      Relation = "admin"
+	Instructor Relation = "instructor" // instructs or moderates a course the data belongs to
+)
+
+// Project converts v into a map holding only the fields the viewer may see.
+// Fields list who may see them in a `view` tag, e.g. `view:"self,admin"`.
+// Fields without the tag are public, fields tagged `view:"-"` are never shown.
+func Project(v interface{}, relations ...Relation) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't project %T, expected a struct", v)
+	}
+
 	data, err := json.Marshal(v)
 	if err != nil {
 		return nil, err
@@ -37,6 +63,66 @@ func RemovedUUID(v interface{}) (map[string]interface{}, error) {
 	if err := json.Unmarshal(data, &m); err != nil {
 		return nil, err
 	}
-	delete(m, "uuid")
+	for name, audience := range audiences(t) {
+		if !visible(audience, relations) {
+			delete(m, name)
+		}
+	}
 	return m, nil
 }
+
+// ProjectList projects every element of a slice.
+func ProjectList[T any](list []T, relations func(T) []Relation) ([]map[string]interface{}, error) {
+	projected := make([]map[string]interface{}, 0, len(list))
+	for _, v := range list {
+		m, err := Project(v, relations(v)...)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, m)
+	}
+	return projected, nil
+}
+
+// audiences maps the json name of every restricted field to the relations allowed to see it.
+func audiences(t reflect.Type) map[string][]string {
+	result := map[string][]string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for name, audience := range audiences(ft) {
+					result[name] = audience
+				}
+			}
+			continue
+		}
+		tag, ok := field.Tag.Lookup("view")
+		if !ok {
+			continue
+		}
+		name := field.Name
+		if jsonTag := strings.Split(field.Tag.Get("json"), ",")[0]; jsonTag != "" {
+			name = jsonTag
+		}
+		if tag == "-" {
+			result[name] = nil
+			continue
+		}
+		result[name] = strings.Split(tag, ",")
+	}
+	return result
+}
+
+func visible(audience []string, relations []Relation) bool {
+	for _, relation := range relations {
+		if slices.Contains(audience, string(relation)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	type profile struct {
+		Name     string `json:"name"`
+		Email    string `json:"email" view:"self,admin,instructor"`
+		Phone    string `json:"phone" view:"self,admin"`
+		Password string `json:"password" view:"-"`
+	}
+	type wrapper struct {
+		*profile
+	}
+	p := &profile{Name: "arnob", Email: "arnob@example.com", Phone: "+8801000000000", Password: "hash"}
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		relations []Relation
+		expected  map[string]interface{}
+	}{
+		{
+			name:      "Public",
+			value:     p,
+			relations: []Relation{Public},
+			expected:  map[string]interface{}{"name": "arnob"},
+		},
+		{
+			name:      "Instructor",
+			value:     p,
+			relations: []Relation{Instructor},
+			expected:  map[string]interface{}{"name": "arnob", "email": "arnob@example.com"},
+		},
+		{
+			name:      "Self",
+			value:     p,
+			relations: []Relation{Public, Self},
+			expected:  map[string]interface{}{"name": "arnob", "email": "arnob@example.com", "phone": "+8801000000000"},
+		},
+		{
+			name:      "PasswordNeverShown",
+			value:     p,
+			relations: []Relation{Self, Admin, Instructor},
+			expected:  map[string]interface{}{"name": "arnob", "email": "arnob@example.com", "phone": "+8801000000000"},
+		},
+		{
+			name:      "EmbeddedStruct",
+			value:     wrapper{p},
+			relations: []Relation{Public},
+			expected:  map[string]interface{}{"name": "arnob"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Project(test.value, test.relations...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
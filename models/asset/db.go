@@ -0,0 +1,109 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package asset
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	KindAvatar      = "avatar"
+	KindCertificate = "certificate"
+
+	// MaxAvatarSize is the largest avatar accepted, in bytes.
+	MaxAvatarSize = 2 << 20
+)
+
+var imageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+var assetMongoNamespace = db.Namespace{Database: "praromvik", Collection: "assets"}
+
+// NewImage checks that data is an image, going by its content rather than what the client claims.
+func NewImage(kind string, owner string, data []byte) (*Asset, error) {
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(imageTypes, contentType) {
+		return nil, fmt.Errorf("unsupported image type %s, expected one of %v", contentType, imageTypes)
+	}
+	return &Asset{
+		ID:          uuid.NewString(),
+		Owner:       owner,
+		Kind:        kind,
+		ContentType: contentType,
+		Data:        data,
+		CreatedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// NewPDF wraps a PDF generated by the server.
+func NewPDF(kind string, owner string, data []byte) *Asset {
+	return &Asset{
+		ID:          uuid.NewString(),
+		Owner:       owner,
+		Kind:        kind,
+		ContentType: "application/pdf",
+		Data:        data,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+func (a *Asset) AddAssetToDB() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{assetMongoNamespace}}
+	_, err := mongoDB.AddDocument(a)
+	return err
+}
+
+func Get(id string) (*Asset, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{assetMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return nil, err
+	}
+	var a Asset
+	if err := result.Decode(&a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func Delete(id string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{assetMongoNamespace}}
+	_, err := mongoDB.DeleteDocument(bson.D{{Key: "_id", Value: id}})
+	return err
+}
+
+// DeleteAll removes every asset uploaded by the user.
+func DeleteAll(owner string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{assetMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "owner", Value: owner}})
+	return err
+}
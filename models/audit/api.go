@@ -0,0 +1,67 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package audit
+
+import "time"
+
+// Event types recorded in the audit log
+const (
+	SignInLockout            = "signin.lockout"
+	SignInCredentialStuffing = "signin.credential_stuffing"
+	SignInUnlock             = "signin.unlock"
+	RoleGrant                = "role.grant"
+	RoleRevoke               = "role.revoke"
+	RoleSetBase              = "role.set_base"
+	ImpersonationStart       = "impersonation.start"
+	ImpersonationStop        = "impersonation.stop"
+	ImpersonationRequest     = "impersonation.request"
+	AccountDeletionRequest   = "account.deletion_request"
+	AccountDeletionCancel    = "account.deletion_cancel"
+	AccountPurge             = "account.purge"
+	ProfileChange            = "user.profile_change"
+	UserSuspend              = "user.suspend"
+	UserBan                  = "user.ban"
+	UserReactivate           = "user.reactivate"
+	UserForcePasswordReset   = "user.force_password_reset"
+	UserImport               = "user.import"
+	InvitationAccept         = "user.invitation_accept"
+	CourseEnroll             = "course.enroll"
+	CourseUnenroll           = "course.unenroll"
+	WaitlistJoin             = "waitlist.join"
+	WaitlistLeave            = "waitlist.leave"
+	CertificateIssue         = "certificate.issue"
+	ExamSubmit               = "exam.submit"
+)
+
+type Event struct {
+	ID        string            `json:"_id" bson:"_id"`
+	Type      string            `json:"type" bson:"type"`
+	Actor     string            `json:"actor" bson:"actor"`
+	Subject   string            `json:"subject" bson:"subject"`
+	IP        string            `json:"ip" bson:"ip"`
+	UserAgent string            `json:"userAgent" bson:"userAgent"`
+	Details   map[string]string `json:"details" bson:"details"`
+	CreatedAt time.Time         `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,87 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package audit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var auditMongoNamespace = db.Namespace{Database: "praromvik", Collection: "audit"}
+
+func (e *Event) Record() error {
+	e.ID = uuid.NewString()
+	e.CreatedAt = time.Now().UTC()
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{auditMongoNamespace}}
+	_, err := mongoDB.AddDocument(e)
+	return err
+}
+
+// Log records the event, and only logs the failure if it couldn't be stored.
+// Use it where losing an audit event must not fail the request itself.
+func Log(e *Event) {
+	if err := e.Record(); err != nil {
+		log.Printf("failed to record audit event %s: %v", e.Type, err)
+	}
+}
+
+// ListAbout returns the events the user performed or was the subject of.
+func ListAbout(userName string) ([]Event, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{auditMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "actor", Value: userName}},
+		bson.D{{Key: "subject", Value: userName}},
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0)
+	if err := cursor.All(context.Background(), &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Anonymize replaces the user name in past events, and drops the addresses they were made from.
+func Anonymize(userName string, anonymous string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{auditMongoNamespace}}
+	if _, err := mongoDB.UpdateMany(
+		bson.D{{Key: "actor", Value: userName}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "actor", Value: anonymous}, {Key: "ip", Value: ""}, {Key: "userAgent", Value: ""}}}},
+	); err != nil {
+		return err
+	}
+	_, err := mongoDB.UpdateMany(
+		bson.D{{Key: "subject", Value: userName}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "subject", Value: anonymous}}}},
+	)
+	return err
+}
@@ -0,0 +1,44 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certificate
+
+import "time"
+
+// Certificate is issued to a student once they complete a course. What it says is kept as issued,
+// even if the course or the user changes later.
+type Certificate struct {
+	ID          string    `json:"-" bson:"_id"` // <user uuid>:<course>, a course is certified once per user
+	Serial      string    `json:"serial" bson:"serial"`
+	UserUUID    string    `json:"-" bson:"userUUID"`
+	Name        string    `json:"name" bson:"name"` // as printed on the certificate
+	Course      string    `json:"course" bson:"course"`
+	CourseTitle string    `json:"courseTitle" bson:"courseTitle"`
+	Instructors []string  `json:"instructors" bson:"instructors"`
+	Recipient   string    `json:"-" bson:"recipient,omitempty"` // hashed email of the user, salted with the serial
+	IssuedAt    time.Time `json:"issuedAt" bson:"issuedAt"`
+	Asset       string    `json:"asset,omitempty" bson:"asset,omitempty"`   // id of the PDF asset
+	SHA256      string    `json:"sha256,omitempty" bson:"sha256,omitempty"` // of the PDF, to check a copy against
+	LedgerIndex *int64    `json:"ledgerIndex,omitempty" bson:"ledgerIndex,omitempty"`
+}
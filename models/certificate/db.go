@@ -0,0 +1,173 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certificate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/praromvik/praromvik/models/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrCertificateNotFound = errors.New("certificate not found")
+
+var certificateMongoNamespace = db.Namespace{Database: "praromvik", Collection: "certificates"}
+
+// Claim stores c unless the user already holds a certificate of the course, and returns the stored one.
+// Concurrent claims end up with the same certificate.
+func Claim(c *Certificate) (*Certificate, error) {
+	c.ID = c.UserUUID + ":" + c.Course
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	result, err := mongoDB.Upsert(bson.D{{Key: "_id", Value: c.ID}}, bson.D{{Key: "$setOnInsert", Value: bson.D{
+		{Key: "serial", Value: c.Serial},
+		{Key: "userUUID", Value: c.UserUUID},
+		{Key: "name", Value: c.Name},
+		{Key: "course", Value: c.Course},
+		{Key: "courseTitle", Value: c.CourseTitle},
+		{Key: "instructors", Value: c.Instructors},
+		{Key: "recipient", Value: c.Recipient},
+		{Key: "issuedAt", Value: c.IssuedAt},
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	return decode(result)
+}
+
+// Get returns the certificate with the serial.
+func Get(serial string) (*Certificate, error) {
+	return find(bson.D{{Key: "serial", Value: serial}})
+}
+
+// GetByCourse returns the certificate of the user for the course.
+func GetByCourse(userUUID string, courseID string) (*Certificate, error) {
+	return find(bson.D{{Key: "_id", Value: userUUID + ":" + courseID}})
+}
+
+// FindByCourse returns one of the certificates issued for the course, which holds a snapshot of it.
+func FindByCourse(courseID string) (*Certificate, error) {
+	return find(bson.D{{Key: "course", Value: courseID}})
+}
+
+// ListByUser returns the certificates issued to the user.
+func ListByUser(userUUID string) ([]Certificate, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(bson.D{{Key: "userUUID", Value: userUUID}})
+	if err != nil {
+		return nil, err
+	}
+	certificates := make([]Certificate, 0)
+	if err := cursor.All(context.Background(), &certificates); err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// SetDocument attaches the rendered PDF to the certificate, unless a concurrent issuance did first.
+// It reports whether this one was attached.
+func (c *Certificate) SetDocument(assetID string, sha256 string) (bool, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: c.ID}, {Key: "asset", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "asset", Value: assetID}, {Key: "sha256", Value: sha256}}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.ModifiedCount == 0 {
+		return false, nil
+	}
+	c.Asset, c.SHA256 = assetID, sha256
+	return true, nil
+}
+
+// SetLedgerIndex records where the certificate was entered in the ledger.
+func (c *Certificate) SetLedgerIndex(index int64) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	_, err := mongoDB.UpdateOne(bson.D{{Key: "_id", Value: c.ID}}, bson.D{{Key: "$set", Value: bson.D{{Key: "ledgerIndex", Value: index}}}})
+	if err != nil {
+		return err
+	}
+	c.LedgerIndex = &index
+	return nil
+}
+
+// SetRecipient stores the hashed email of the user, for a certificate issued before it was stored.
+func (c *Certificate) SetRecipient(identity string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	_, err := mongoDB.UpdateOne(bson.D{{Key: "_id", Value: c.ID}}, bson.D{{Key: "$set", Value: bson.D{{Key: "recipient", Value: identity}}}})
+	if err != nil {
+		return err
+	}
+	c.Recipient = identity
+	return nil
+}
+
+// ListUnledgered returns the certificates whose PDF is rendered, but which aren't in the ledger yet.
+func ListUnledgered() ([]Certificate, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	cursor, err := mongoDB.ListPage(bson.D{
+		{Key: "asset", Value: bson.D{{Key: "$exists", Value: true}}},
+		{Key: "ledgerIndex", Value: bson.D{{Key: "$exists", Value: false}}},
+	}, bson.D{{Key: "issuedAt", Value: 1}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	certificates := make([]Certificate, 0)
+	if err := cursor.All(context.Background(), &certificates); err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// DeleteByUser removes the certificates issued to the user.
+func DeleteByUser(userUUID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "userUUID", Value: userUUID}})
+	return err
+}
+
+func find(filter bson.D) (*Certificate, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{certificateMongoNamespace}}
+	result, err := mongoDB.GetDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	return decode(result)
+}
+
+func decode(result *mongo.SingleResult) (*Certificate, error) {
+	var c Certificate
+	if err := result.Decode(&c); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
@@ -24,6 +24,16 @@ SOFTWARE.
 
 package course
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/praromvik/praromvik/pkg/assessment"
+)
+
+// ContentQuiz is the type of the contents which are quizzes drawn from the question bank of the course.
+const ContentQuiz = "quiz"
+
 type Course struct {
 	CourseId    string   `json:"_id" bson:"_id"`
 	Title       string   `json:"title" bson:"title"`
@@ -34,7 +44,8 @@ type Course struct {
 	EndDate     string   `json:"endDate" bson:"endDate"`
 	Duration    int      `json:"duration" bson:"duration"` // Duration in week
 	Capacity    int      `json:"capacity" bson:"capacity"`
-	Students    []string `json:"students" bson:"students"`
+	Students    []string `json:"students" bson:"students" view:"admin,instructor"`
+	Reserved    []string `json:"reserved" bson:"reserved" view:"admin,instructor"` // seats offered to the waitlist
 	Price       int      `json:"price" bson:"price"`
 	Image       []byte   `json:"image" bson:"-"`
 }
@@ -53,4 +64,64 @@ type Content struct {
 	Title     string `json:"title" bson:"title"`
 	Type      string `json:"type" bson:"type"` // video, resource, quiz, lab
 	Data      []byte `json:"data" bson:"data"`
+	Quiz      *Quiz  `json:"quiz,omitempty" bson:"quiz,omitempty"` // of quiz contents
+}
+
+// Quiz is how a quiz draws its questions from the question bank of the course. Every student gets their
+// own draw, seeded by the quiz & the student, with the options of choice questions shuffled.
+type Quiz struct {
+	Pools       []assessment.Pool `json:"pools" bson:"pools"`
+	PassPercent float64           `json:"passPercent" bson:"passPercent"`
+	MaxAttempts int               `json:"maxAttempts" bson:"maxAttempts"` // per student, any number if zero
+	Seed        string            `json:"-" bson:"seed"`
+}
+
+// QuizDraw is the quiz drawn for a student, kept as drawn even if the bank changes, with their last answers.
+// Their best score is kept apart, so that a later submission doesn't undo a pass.
+type QuizDraw struct {
+	ID          string                `json:"-" bson:"_id"` // <user uuid>:<course>:<content>
+	UserUUID    string                `json:"-" bson:"userUUID"`
+	Course      string                `json:"course" bson:"course"`
+	Content     string                `json:"content" bson:"content"`
+	Questions   []assessment.Question `json:"-" bson:"questions"` // with their answers
+	DrawnAt     time.Time             `json:"drawnAt" bson:"drawnAt"`
+	Answers     []assessment.Answer   `json:"answers" bson:"answers"`
+	Score       *assessment.Score     `json:"score,omitempty" bson:"score,omitempty"`
+	SubmittedAt *time.Time            `json:"submittedAt,omitempty" bson:"submittedAt,omitempty"`
+	Attempts    int                   `json:"attempts" bson:"attempts"`
+	Best        *assessment.Score     `json:"best,omitempty" bson:"best,omitempty"`
+	PassedAt    *time.Time            `json:"passedAt,omitempty" bson:"passedAt,omitempty"`
+}
+
+func (q *Quiz) Validate() error {
+	if len(q.Pools) == 0 {
+		return fmt.Errorf("a quiz draws from at least one pool")
+	}
+	for _, pool := range q.Pools {
+		if err := pool.Validate(); err != nil {
+			return err
+		}
+	}
+	if q.PassPercent < 0 || q.PassPercent > 100 {
+		return fmt.Errorf("pass percent must be between 0 and 100")
+	}
+	if q.MaxAttempts < 0 {
+		return fmt.Errorf("max attempts can't be negative")
+	}
+	return nil
+}
+
+// Progress is how far a student got in a content.
+type Progress struct {
+	ID          string     `json:"-" bson:"_id"` // <user uuid>:<course>:<content>
+	UserUUID    string     `json:"-" bson:"userUUID"`
+	Course      string     `json:"course" bson:"course"`
+	Lesson      string     `json:"lesson" bson:"lesson"`
+	Content     string     `json:"content" bson:"content"`
+	Percent     int        `json:"percent" bson:"percent"`
+	Position    float64    `json:"position" bson:"position"`
+	Completed   bool       `json:"completed" bson:"completed"`
+	StartedAt   time.Time  `json:"startedAt" bson:"startedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt" bson:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
 }
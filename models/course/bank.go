@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/pkg/assessment"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrInvalidQuestion  = errors.New("invalid question")
+)
+
+// bankNamespace is where the question bank of the course lives, next to its lessons & contents.
+func bankNamespace(courseID string) db.Namespace {
+	return db.Namespace{Database: courseID, Collection: "questions"}
+}
+
+// AddQuestion adds the question to the bank of the course, with a new id.
+func AddQuestion(courseID string, q *assessment.Question) error {
+	q.ID = uuid.NewString()
+	if err := q.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuestion, err)
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{bankNamespace(courseID)}}
+	_, err := mongoDB.AddDocument(q)
+	return err
+}
+
+// GetQuestion returns the question of the bank of the course with the given id.
+func GetQuestion(courseID string, id string) (*assessment.Question, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{bankNamespace(courseID)}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "id", Value: id}})
+	if err != nil {
+		return nil, err
+	}
+	var q assessment.Question
+	if err := result.Decode(&q); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return &q, nil
+}
+
+// ListQuestions returns the questions of the bank of the course, only those with the tag or of the difficulty if set.
+func ListQuestions(courseID string, tag string, difficulty string) ([]assessment.Question, error) {
+	filter := bson.D{}
+	if tag != "" {
+		filter = append(filter, bson.E{Key: "tags", Value: tag})
+	}
+	if difficulty != "" {
+		filter = append(filter, bson.E{Key: "difficulty", Value: difficulty})
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{bankNamespace(courseID)}}
+	cursor, err := mongoDB.ListDocuments(filter)
+	if err != nil {
+		return nil, err
+	}
+	questions := make([]assessment.Question, 0)
+	if err := cursor.All(context.Background(), &questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// UpdateQuestion replaces the question in the bank. Quizzes already drawn keep it as it was.
+func UpdateQuestion(courseID string, q *assessment.Question) error {
+	if err := q.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuestion, err)
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{bankNamespace(courseID)}}
+	result, err := mongoDB.UpdateDocument(bson.D{{Key: "id", Value: q.ID}}, q)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrQuestionNotFound
+	}
+	return nil
+}
+
+// DeleteQuestion removes the question from the bank. Quizzes already drawn keep it.
+func DeleteQuestion(courseID string, id string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{bankNamespace(courseID)}}
+	result, err := mongoDB.DeleteDocument(bson.D{{Key: "id", Value: id}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrQuestionNotFound
+	}
+	return nil
+}
+
+// DeleteBank removes the question bank of a deleted course.
+func DeleteBank(courseID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{bankNamespace(courseID)}}
+	_, err := mongoDB.DeleteMany(bson.D{})
+	return err
+}
@@ -26,13 +26,16 @@ package course
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go/types"
 	"net/http"
 	"reflect"
+	"slices"
 
 	"github.com/praromvik/praromvik/models/db"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func Get(document Document) (any, error) {
@@ -138,3 +141,128 @@ func ValidateNameUniqueness(document Document) (int, error) {
 	}
 	return http.StatusOK, nil
 }
+
+// GetCourse fetches the course with the given id.
+func GetCourse(id string) (*Course, error) {
+	document, err := Get(&Course{CourseId: id})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrCourseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, ok := document.(*Course)
+	if !ok {
+		return nil, fmt.Errorf("document is not of type %T", c)
+	}
+	return c, nil
+}
+
+// UpdateCourse sets the fields of the course given in c, leaving the others as they are. Students &
+// reserved seats are changed by enrollment alone, an edit never writes them back.
+func UpdateCourse(c *Course) error {
+	set := bson.D{}
+	for _, field := range []struct {
+		key   string
+		value interface{}
+		set   bool
+	}{
+		{"title", c.Title, c.Title != ""},
+		{"description", c.Description, c.Description != ""},
+		{"instructors", c.Instructors, c.Instructors != nil},
+		{"moderators", c.Moderators, c.Moderators != nil},
+		{"startDate", c.StartDate, c.StartDate != ""},
+		{"endDate", c.EndDate, c.EndDate != ""},
+		{"duration", c.Duration, c.Duration != 0},
+		{"capacity", c.Capacity, c.Capacity != 0},
+		{"price", c.Price, c.Price != 0},
+	} {
+		if field.set {
+			set = append(set, bson.E{Key: field.key, Value: field.value})
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{c.GetNamespace()}}
+	result, err := mongoDB.UpdateOne(bson.D{{Key: "_id", Value: c.CourseId}}, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrCourseNotFound
+	}
+	return nil
+}
+
+// GetContent fetches the content with the given id from the course.
+func GetContent(courseRef string, id string) (*Content, error) {
+	document, err := Get(&Content{CourseRef: courseRef, ContentID: id})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrContentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, ok := document.(*Content)
+	if !ok {
+		return nil, fmt.Errorf("document is not of type %T", c)
+	}
+	return c, nil
+}
+
+// IsStaffOfStudent reports whether staff instructs or moderates any course the student is enrolled in.
+func IsStaffOfStudent(staff string, student string) (bool, error) {
+	filter := bson.D{
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "instructors", Value: staff}},
+			bson.D{{Key: "moderators", Value: staff}},
+		}},
+		{Key: "students", Value: student},
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	count, err := mongoDB.CountDocuments(filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsStaff reports whether the user instructs or moderates the course.
+func (c *Course) IsStaff(userName string) bool {
+	return slices.Contains(c.Instructors, userName) || slices.Contains(c.Moderators, userName)
+}
+
+// ListByMember returns the courses the user studies in, instructs or moderates.
+func ListByMember(userName string) ([]Course, error) {
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "students", Value: userName}},
+		bson.D{{Key: "instructors", Value: userName}},
+		bson.D{{Key: "moderators", Value: userName}},
+	}}}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	cursor, err := mongoDB.ListDocuments(filter)
+	if err != nil {
+		return nil, err
+	}
+	courses := make([]Course, 0)
+	if err := cursor.All(context.Background(), &courses); err != nil {
+		return nil, err
+	}
+	return courses, nil
+}
+
+// ReplaceMember replaces the user name in the member lists of every course,
+// when the user is renamed or anonymized.
+func ReplaceMember(userName string, replacement string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	for _, field := range []string{"students", "reserved", "instructors", "moderators"} {
+		if _, err := mongoDB.UpdateMany(
+			bson.D{{Key: field, Value: userName}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: field + ".$", Value: replacement}}}},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	ErrCourseNotFound  = errors.New("course not found")
+	ErrContentNotFound = errors.New("content not found")
+	ErrCourseFull      = errors.New("the course is full")
+	ErrCourseEnded     = errors.New("the course has ended")
+	ErrAlreadyEnrolled = errors.New("already enrolled in the course")
+	ErrNotEnrolled     = errors.New("not enrolled in the course")
+	ErrSeatReserved    = errors.New("a seat of the course is already reserved for the user")
+	ErrNoReservedSeat  = errors.New("no seat of the course is reserved for the user")
+)
+
+// dateLayouts are the accepted formats of the start & end dates of a course.
+var dateLayouts = []string{time.DateOnly, time.RFC3339}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD or RFC 3339", value)
+}
+
+// ValidateDates checks that the start & end dates, when set, can be parsed & are in order.
+func (c *Course) ValidateDates() error {
+	var start, end time.Time
+	var err error
+	if c.StartDate != "" {
+		if start, err = parseDate(c.StartDate); err != nil {
+			return err
+		}
+	}
+	if c.EndDate != "" {
+		if end, err = parseDate(c.EndDate); err != nil {
+			return err
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("the course can't end before it starts")
+	}
+	return nil
+}
+
+// Ended reports whether the course is over at the given time. A date-only end date includes the whole day.
+// A course without an end date never ends.
+func (c *Course) Ended(now time.Time) bool {
+	if c.EndDate == "" {
+		return false
+	}
+	end, err := parseDate(c.EndDate)
+	if err != nil {
+		return false
+	}
+	if len(c.EndDate) == len(time.DateOnly) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return !now.Before(end)
+}
+
+// SeatsLeft returns how many more students the course takes, or -1 if it has no capacity.
+// Seats offered to the waitlist are taken.
+func (c *Course) SeatsLeft() int {
+	if c.Capacity <= 0 {
+		return -1
+	}
+	return max(c.Capacity-len(c.Students)-len(c.Reserved), 0)
+}
+
+// AddStudent enrolls the user in the course, as long as it has a seat left.
+// The capacity check & the enrollment are a single update, so concurrent enrollments can't overbook the course.
+func AddStudent(courseID string, userName string) error {
+	return takeSeat(courseID, userName, "students")
+}
+
+// ReserveSeat keeps a seat of the course for the user, until they claim it or the offer is withdrawn.
+func ReserveSeat(courseID string, userName string) error {
+	return takeSeat(courseID, userName, "reserved")
+}
+
+func takeSeat(courseID string, userName string, field string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{
+			{Key: "_id", Value: courseID},
+			{Key: "students", Value: bson.D{{Key: "$ne", Value: userName}}},
+			{Key: "reserved", Value: bson.D{{Key: "$ne", Value: userName}}},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "capacity", Value: bson.D{{Key: "$lte", Value: 0}}}},
+				bson.D{{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{
+					bson.D{{Key: "$add", Value: bson.A{arraySize("students"), arraySize("reserved")}}},
+					"$capacity",
+				}}}}},
+			}},
+		},
+		appendTo(field, userName),
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+	// Nothing matched, find out why.
+	c, err := GetCourse(courseID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(c.Students, userName) {
+		return ErrAlreadyEnrolled
+	}
+	if slices.Contains(c.Reserved, userName) {
+		return ErrSeatReserved
+	}
+	return ErrCourseFull
+}
+
+// ClaimSeat turns the seat reserved for the user into an enrollment.
+func ClaimSeat(courseID string, userName string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	update := appendTo("students", userName)
+	update = append(update, bson.D{{Key: "$set", Value: bson.D{{Key: "reserved", Value: bson.D{{Key: "$filter", Value: bson.D{
+		{Key: "input", Value: "$reserved"},
+		{Key: "cond", Value: bson.D{{Key: "$ne", Value: bson.A{"$$this", bson.D{{Key: "$literal", Value: userName}}}}}},
+	}}}}}}})
+	result, err := mongoDB.UpdateOne(bson.D{{Key: "_id", Value: courseID}, {Key: "reserved", Value: userName}}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNoReservedSeat
+	}
+	return nil
+}
+
+// ReleaseSeat gives the seat reserved for the user back.
+func ReleaseSeat(courseID string, userName string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	_, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: courseID}, {Key: "reserved", Value: userName}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "reserved", Value: userName}}}},
+	)
+	return err
+}
+
+// RemoveStudent drops the user from the students of the course.
+func RemoveStudent(courseID string, userName string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{(&Course{}).GetNamespace()}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: courseID}, {Key: "students", Value: userName}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "students", Value: userName}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotEnrolled
+	}
+	return nil
+}
+
+// appendTo returns the update pipeline appending the value to the array field, which may still be null.
+func appendTo(field string, value interface{}) bson.A {
+	return bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: field, Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+		bson.D{{Key: "$ifNull", Value: bson.A{"$" + field, bson.A{}}}},
+		bson.A{bson.D{{Key: "$literal", Value: value}}},
+	}}}}}}}}
+}
+
+func arraySize(field string) bson.D {
+	return bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + field, bson.A{}}}}}}
+}
+
+// IsStudent reports whether the user is enrolled in the course.
+func (c *Course) IsStudent(userName string) bool {
+	return slices.Contains(c.Students, userName)
+}
@@ -0,0 +1,98 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		valid bool
+	}{
+		{name: "None", valid: true},
+		{name: "Dates", start: "2024-05-01", end: "2024-06-30", valid: true},
+		{name: "RFC3339", start: "2024-05-01T09:00:00Z", end: "2024-06-30T17:00:00Z", valid: true},
+		{name: "EndBeforeStart", start: "2024-06-30", end: "2024-05-01", valid: false},
+		{name: "Malformed", start: "01/05/2024", valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Course{StartDate: test.start, EndDate: test.end}
+			if valid := c.ValidateDates() == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v", test.valid, valid)
+			}
+		})
+	}
+}
+
+func TestEnded(t *testing.T) {
+	tests := []struct {
+		name     string
+		end      string
+		now      time.Time
+		expected bool
+	}{
+		{name: "NoEnd", now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), expected: false},
+		{name: "LastDay", end: "2024-06-30", now: time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), expected: false},
+		{name: "DayAfter", end: "2024-06-30", now: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), expected: true},
+		{name: "BeforeTime", end: "2024-06-30T17:00:00Z", now: time.Date(2024, 6, 30, 16, 59, 0, 0, time.UTC), expected: false},
+		{name: "AtTime", end: "2024-06-30T17:00:00Z", now: time.Date(2024, 6, 30, 17, 0, 0, 0, time.UTC), expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Course{EndDate: test.end}
+			if got := c.Ended(test.now); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSeatsLeft(t *testing.T) {
+	tests := []struct {
+		name     string
+		course   Course
+		expected int
+	}{
+		{name: "NoCapacity", course: Course{Students: []string{"student-1"}}, expected: -1},
+		{name: "Open", course: Course{Capacity: 3, Students: []string{"student-1"}}, expected: 2},
+		{name: "Full", course: Course{Capacity: 1, Students: []string{"student-1"}}, expected: 0},
+		{name: "Overbooked", course: Course{Capacity: 1, Students: []string{"student-1", "student-2"}}, expected: 0},
+		{name: "SeatOffered", course: Course{Capacity: 3, Students: []string{"student-1"}, Reserved: []string{"student-2"}}, expected: 1},
+		{name: "LastSeatOffered", course: Course{Capacity: 2, Students: []string{"student-1"}, Reserved: []string{"student-2"}}, expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.course.SeatsLeft(); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
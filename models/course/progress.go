@@ -0,0 +1,130 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"context"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/pkg/progress"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var progressMongoNamespace = db.Namespace{Database: "praromvik", Collection: "progress"}
+
+// RecordProgress applies the event of the user on the content. Progress only moves forward:
+// the percentage never drops & a completed content stays completed, whatever order the events arrive in.
+func RecordProgress(userUUID string, c *Content, e progress.Event) (*Progress, error) {
+	now := time.Now().UTC()
+	percent := e.Percent
+	set := bson.D{{Key: "lesson", Value: c.LessonRef}, {Key: "updatedAt", Value: now}}
+	if e.Type != progress.Started {
+		set = append(set, bson.E{Key: "position", Value: e.Position})
+	}
+	update := bson.D{{Key: "$setOnInsert", Value: bson.D{
+		{Key: "userUUID", Value: userUUID},
+		{Key: "course", Value: c.CourseRef},
+		{Key: "content", Value: c.ContentID},
+		{Key: "startedAt", Value: now},
+	}}}
+	if e.Completes() {
+		percent = 100
+		set = append(set, bson.E{Key: "completed", Value: true})
+		update = append(update, bson.E{Key: "$min", Value: bson.D{{Key: "completedAt", Value: now}}})
+	}
+	update = append(update,
+		bson.E{Key: "$set", Value: set},
+		bson.E{Key: "$max", Value: bson.D{{Key: "percent", Value: percent}}},
+	)
+
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{progressMongoNamespace}}
+	result, err := mongoDB.Upsert(bson.D{{Key: "_id", Value: userUUID + ":" + c.CourseRef + ":" + c.ContentID}}, update)
+	if err != nil {
+		return nil, err
+	}
+	var p Progress
+	if err := result.Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListProgress returns the progress of the user in the course, or in every course if courseID is empty.
+func ListProgress(userUUID string, courseID string) ([]Progress, error) {
+	filter := bson.D{{Key: "userUUID", Value: userUUID}}
+	if courseID != "" {
+		filter = append(filter, bson.E{Key: "course", Value: courseID})
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{progressMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(filter)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Progress, 0)
+	if err := cursor.All(context.Background(), &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Summarize aggregates the progress of the user to the lessons & the course.
+func Summarize(courseID string, records []Progress) (*progress.Summary, error) {
+	documents, err := List(&Lesson{CourseRef: courseID})
+	if err != nil {
+		return nil, err
+	}
+	lessons := make([]progress.Lesson, 0)
+	for _, l := range *documents.(*[]Lesson) {
+		lessons = append(lessons, progress.Lesson{ID: l.LessonID, Title: l.Title, Contents: l.Contents})
+	}
+	converted := make([]progress.Record, 0, len(records))
+	for _, p := range records {
+		converted = append(converted, progress.Record{
+			Content:   p.Content,
+			Percent:   p.Percent,
+			Position:  p.Position,
+			Completed: p.Completed,
+			UpdatedAt: p.UpdatedAt,
+		})
+	}
+	summary := progress.Summarize(lessons, converted)
+	return &summary, nil
+}
+
+// DeleteProgress forgets the progress of the user in every course.
+func DeleteProgress(userUUID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{progressMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "userUUID", Value: userUUID}})
+	return err
+}
+
+// DeleteCourseProgress forgets the progress of every student in a deleted course.
+func DeleteCourseProgress(courseID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{progressMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "course", Value: courseID}})
+	return err
+}
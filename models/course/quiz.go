@@ -0,0 +1,188 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/pkg/assessment"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrNotQuiz           = errors.New("the content isn't a quiz")
+	ErrQuizNotDrawn      = errors.New("the quiz wasn't drawn for the student yet")
+	ErrInvalidQuizAnswer = errors.New("invalid answer")
+	ErrNoQuizAttempts    = errors.New("no attempt at the quiz is left")
+	ErrQuizNotSubmitted  = errors.New("a quiz is completed by passing it, not by a progress event")
+	// ErrQuizConflict means the quiz was submitted concurrently.
+	ErrQuizConflict = errors.New("the quiz was submitted concurrently")
+)
+
+var quizMongoNamespace = db.Namespace{Database: "praromvik", Collection: "quizzes"}
+
+// DrawQuiz returns the questions of the quiz drawn for the student, drawing them on the first call.
+// The draw is seeded by the quiz & the student, and kept, so the student always sees the same quiz.
+func DrawQuiz(userUUID string, c *Content) (*QuizDraw, error) {
+	if c.Type != ContentQuiz || c.Quiz == nil {
+		return nil, ErrNotQuiz
+	}
+	id := userUUID + ":" + c.CourseRef + ":" + c.ContentID
+	if d, err := GetQuizDraw(id); !errors.Is(err, ErrQuizNotDrawn) {
+		return d, err
+	}
+
+	bank, err := ListQuestions(c.CourseRef, "", "")
+	if err != nil {
+		return nil, err
+	}
+	questions, err := assessment.Draw(bank, c.Quiz.Pools, c.Quiz.Seed+":"+userUUID)
+	if err != nil {
+		return nil, err
+	}
+	// Drawn concurrently, the first draw stored is kept.
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{quizMongoNamespace}}
+	result, err := mongoDB.Upsert(bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$setOnInsert", Value: bson.D{
+		{Key: "userUUID", Value: userUUID},
+		{Key: "course", Value: c.CourseRef},
+		{Key: "content", Value: c.ContentID},
+		{Key: "questions", Value: questions},
+		{Key: "drawnAt", Value: time.Now().UTC()},
+		{Key: "answers", Value: bson.A{}},
+		{Key: "attempts", Value: 0},
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	return decodeQuizDraw(result)
+}
+
+// GetQuizDraw returns the quiz drawn with the given id.
+func GetQuizDraw(id string) (*QuizDraw, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{quizMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return nil, err
+	}
+	return decodeQuizDraw(result)
+}
+
+// Submit grades the answers to every question of the draw, and keeps them with their score. A quiz may be
+// submitted again while the quiz has attempts left; the last submission counts, but the best score & the
+// time the quiz was first passed are kept.
+func (d *QuizDraw) Submit(q *Quiz, answers []assessment.Answer, now time.Time) error {
+	if d.Final(q) {
+		return ErrNoQuizAttempts
+	}
+	merged, err := assessment.Merge(d.Questions, nil, answers)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuizAnswer, err)
+	}
+	if len(merged) < len(d.Questions) {
+		return fmt.Errorf("%w: only %d of the %d questions are answered", ErrInvalidQuizAnswer, len(merged), len(d.Questions))
+	}
+	score := assessment.Grade(d.Questions, merged, q.PassPercent)
+	submittedAt := now.UTC()
+	best, passedAt := d.Best, d.PassedAt
+	if best == nil || score.Percent > best.Percent {
+		best = &score
+	}
+	if passedAt == nil && score.Passed {
+		passedAt = &submittedAt
+	}
+	set := bson.D{
+		{Key: "answers", Value: merged},
+		{Key: "score", Value: score},
+		{Key: "submittedAt", Value: submittedAt},
+		{Key: "attempts", Value: d.Attempts + 1},
+		{Key: "best", Value: best},
+	}
+	if passedAt != nil {
+		set = append(set, bson.E{Key: "passedAt", Value: *passedAt})
+	}
+	// Counting the attempts read, a concurrent submission doesn't get past the limit.
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{quizMongoNamespace}}
+	result, err := mongoDB.UpdateOne(bson.D{{Key: "_id", Value: d.ID}, {Key: "attempts", Value: d.Attempts}}, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrQuizConflict
+	}
+	d.Answers, d.Score, d.SubmittedAt = merged, &score, &submittedAt
+	d.Attempts, d.Best, d.PassedAt = d.Attempts+1, best, passedAt
+	return nil
+}
+
+// Final reports whether the quiz has no attempts left, after which the results of each question are shown.
+// They are never shown for a quiz which may be submitted any number of times.
+func (d *QuizDraw) Final(q *Quiz) bool {
+	return q.MaxAttempts > 0 && d.Attempts >= q.MaxAttempts
+}
+
+// ListQuizDraws returns the quizzes drawn for the student in every course.
+func ListQuizDraws(userUUID string) ([]QuizDraw, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{quizMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(bson.D{{Key: "userUUID", Value: userUUID}})
+	if err != nil {
+		return nil, err
+	}
+	draws := make([]QuizDraw, 0)
+	if err := cursor.All(context.Background(), &draws); err != nil {
+		return nil, err
+	}
+	return draws, nil
+}
+
+// DeleteQuizDraws forgets the quizzes drawn for the student in every course.
+func DeleteQuizDraws(userUUID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{quizMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "userUUID", Value: userUUID}})
+	return err
+}
+
+// DeleteCourseQuizDraws forgets the quizzes drawn in a deleted course.
+func DeleteCourseQuizDraws(courseID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{quizMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "course", Value: courseID}})
+	return err
+}
+
+func decodeQuizDraw(result *mongo.SingleResult) (*QuizDraw, error) {
+	var d QuizDraw
+	if err := result.Decode(&d); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrQuizNotDrawn
+		}
+		return nil, err
+	}
+	return &d, nil
+}
@@ -63,7 +63,7 @@ func TestMongoDBConnection(ctx context.Context, mClient *mongo.Client) error {
 	}
 
 	collection := mClient.Database("testing").Collection("numbers")
-	res, err := collection.InsertOne(ctx, bson.D{{"name", "pi"}, {"value", 3.14159}})
+	res, err := collection.InsertOne(ctx, bson.D{{Key: "name", Value: "pi"}, {Key: "value", Value: 3.14159}})
 	if err != nil {
 		return err
 	}
@@ -62,3 +62,19 @@ func (_ Firestore) UpdateDocument(collName string, id string, data interface{})
 	fmt.Printf("Update Document with id %s\n", id)
 	return nil
 }
+
+func (_ Firestore) DeleteDocument(collName string, id string) error {
+	_, err := client.Firestore.Collection(collName).Doc(id).Delete(context.Background())
+	return err
+}
+
+// MergeDocument only overwrites the given fields of the document.
+func (_ Firestore) MergeDocument(collName string, id string, data map[string]interface{}) error {
+	_, err := client.Firestore.Collection(collName).Doc(id).Set(context.Background(), data, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Merged Document with id %s\n", id)
+	return nil
+}
@@ -62,6 +62,30 @@ func (m Mongo) UpdateDocument(filter interface{}, newData interface{}) (*mongo.U
 	return collection.ReplaceOne(context.TODO(), filter, newData)
 }
 
+func (m Mongo) UpdateOne(filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	collection, err := m.getDBCollection()
+	if err != nil {
+		return nil, err
+	}
+	return collection.UpdateOne(context.TODO(), filter, update)
+}
+
+func (m Mongo) UpdateMany(filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	collection, err := m.getDBCollection()
+	if err != nil {
+		return nil, err
+	}
+	return collection.UpdateMany(context.TODO(), filter, update)
+}
+
+func (m Mongo) DeleteMany(filter interface{}) (*mongo.DeleteResult, error) {
+	collection, err := m.getDBCollection()
+	if err != nil {
+		return nil, err
+	}
+	return collection.DeleteMany(context.TODO(), filter)
+}
+
 func (m Mongo) DeleteDocument(filter interface{}) (*mongo.DeleteResult, error) {
 	collection, err := m.getDBCollection()
 	if err != nil {
@@ -78,6 +102,38 @@ func (m Mongo) ListDocuments(filter interface{}) (*mongo.Cursor, error) {
 	return collection.Find(context.Background(), filter)
 }
 
+// ListPage returns at most limit documents matching the filter, in the given order, after skipping the first skip.
+func (m Mongo) ListPage(filter interface{}, sort interface{}, skip int64, limit int64) (*mongo.Cursor, error) {
+	collection, err := m.getDBCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(sort).SetSkip(skip).SetLimit(limit)
+	return collection.Find(context.Background(), filter, opts)
+}
+
+// FindOneAndUpdate atomically updates the first document matching the filter, in the given order,
+// and returns it as it is after the update.
+func (m Mongo) FindOneAndUpdate(filter interface{}, sort interface{}, update interface{}) (*mongo.SingleResult, error) {
+	collection, err := m.getDBCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.FindOneAndUpdate().SetSort(sort).SetReturnDocument(options.After)
+	return collection.FindOneAndUpdate(context.Background(), filter, update, opts), nil
+}
+
+// Upsert atomically updates the document matching the filter, creating it if there is none,
+// and returns it as it is after the update.
+func (m Mongo) Upsert(filter interface{}, update interface{}) (*mongo.SingleResult, error) {
+	collection, err := m.getDBCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return collection.FindOneAndUpdate(context.Background(), filter, update, opts), nil
+}
+
 func (m Mongo) CountDocuments(filter interface{}) (int64, error) {
 	collection, err := m.getDBCollection()
 	if err != nil {
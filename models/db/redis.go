@@ -0,0 +1,118 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db/client"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type Redis struct{}
+
+func (_ Redis) Exists(key string) (bool, error) {
+	n, err := client.Redis.Exists(context.Background(), key).Result()
+	return n > 0, err
+}
+
+func (_ Redis) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return client.Redis.Del(context.Background(), keys...).Err()
+}
+
+func (_ Redis) Expire(key string, expiration time.Duration) error {
+	return client.Redis.Expire(context.Background(), key, expiration).Err()
+}
+
+func (_ Redis) AddToSet(key string, members ...interface{}) error {
+	return client.Redis.SAdd(context.Background(), key, members...).Err()
+}
+
+func (_ Redis) RemoveFromSet(key string, members ...interface{}) error {
+	return client.Redis.SRem(context.Background(), key, members...).Err()
+}
+
+func (_ Redis) SetMembers(key string) ([]string, error) {
+	return client.Redis.SMembers(context.Background(), key).Result()
+}
+
+func (_ Redis) SetHash(key string, values map[string]interface{}) error {
+	return client.Redis.HSet(context.Background(), key, values).Err()
+}
+
+func (_ Redis) GetHash(key string) (map[string]string, error) {
+	return client.Redis.HGetAll(context.Background(), key).Result()
+}
+
+func (_ Redis) Set(key string, value interface{}, expiration time.Duration) error {
+	return client.Redis.Set(context.Background(), key, value, expiration).Err()
+}
+
+// Get returns the value at key, or an empty string if it doesn't exist.
+func (_ Redis) Get(key string) (string, error) {
+	value, err := client.Redis.Get(context.Background(), key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetIfAbsent sets the key only if it doesn't exist yet, and reports whether it did so.
+func (_ Redis) SetIfAbsent(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return client.Redis.SetNX(context.Background(), key, value, expiration).Result()
+}
+
+// Increment increases the counter at key by one. A new counter expires after the given duration.
+func (_ Redis) Increment(key string, expiration time.Duration) (int64, error) {
+	n, err := client.Redis.Incr(context.Background(), key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		if err := client.Redis.Expire(context.Background(), key, expiration).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// TTL returns the remaining time to live of the key, or zero if it doesn't expire or exist.
+func (_ Redis) TTL(key string) (time.Duration, error) {
+	ttl, err := client.Redis.TTL(context.Background(), key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+func (_ Redis) SetSize(key string) (int64, error) {
+	return client.Redis.SCard(context.Background(), key).Result()
+}
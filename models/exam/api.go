@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package exam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/pkg/assessment"
+)
+
+// Statuses of an attempt
+const (
+	StatusInProgress = "in_progress"
+	StatusSubmitted  = "submitted"
+)
+
+// Exam is a timed test of a course. Students see its questions only within an attempt, without the answers.
+type Exam struct {
+	ExamID        string                `json:"_id" bson:"_id"`
+	CourseRef     string                `json:"courseRef" bson:"courseRef"`
+	Title         string                `json:"title" bson:"title"`
+	Description   string                `json:"description" bson:"description"`
+	Opens         time.Time             `json:"opens" bson:"opens"`
+	Closes        time.Time             `json:"closes" bson:"closes"`
+	Duration      int                   `json:"duration" bson:"duration"`       // of an attempt, in minutes
+	MaxAttempts   int                   `json:"maxAttempts" bson:"maxAttempts"` // per student, any number if zero
+	PassPercent   float64               `json:"passPercent" bson:"passPercent"`
+	QuestionCount int                   `json:"questionCount" bson:"questionCount"`
+	Questions     []assessment.Question `json:"questions" bson:"questions" view:"admin,instructor"`
+}
+
+// Attempt is a student taking an exam. Answers are saved as the student goes, until they submit
+// or the time runs out; the attempt is then scored.
+type Attempt struct {
+	ID            string              `json:"-" bson:"_id"` // <user uuid>:<exam>:<number>
+	UserUUID      string              `json:"-" bson:"userUUID"`
+	Exam          string              `json:"exam" bson:"exam"`
+	Course        string              `json:"course" bson:"course"`
+	Number        int                 `json:"number" bson:"number"` // 1 for the first attempt of the student
+	Status        string              `json:"status" bson:"status"`
+	StartedAt     time.Time           `json:"startedAt" bson:"startedAt"`
+	Deadline      time.Time           `json:"deadline" bson:"deadline"`
+	SavedAt       *time.Time          `json:"savedAt,omitempty" bson:"savedAt,omitempty"`
+	SubmittedAt   *time.Time          `json:"submittedAt,omitempty" bson:"submittedAt,omitempty"`
+	AutoSubmitted bool                `json:"autoSubmitted,omitempty" bson:"autoSubmitted,omitempty"` // the time ran out first
+	Answers       []assessment.Answer `json:"answers" bson:"answers"`
+	Score         *assessment.Score   `json:"score,omitempty" bson:"score,omitempty"`
+	Revision      int64               `json:"-" bson:"revision"` // bumped by every save, against lost updates
+}
+
+func (e *Exam) Schedule() assessment.Schedule {
+	return assessment.Schedule{
+		Opens:    e.Opens,
+		Closes:   e.Closes,
+		Duration: time.Duration(e.Duration) * time.Minute,
+		Attempts: e.MaxAttempts,
+	}
+}
+
+func (e *Exam) Validate() error {
+	if strings.TrimSpace(e.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if err := e.Schedule().Validate(); err != nil {
+		return err
+	}
+	if e.PassPercent < 0 || e.PassPercent > 100 {
+		return fmt.Errorf("pass percent must be between 0 and 100")
+	}
+	return assessment.ValidateQuestions(e.Questions)
+}
@@ -0,0 +1,196 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package exam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/pkg/assessment"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrAttemptNotFound = errors.New("no attempt of the exam is in progress")
+	// ErrAttemptConflict means the attempt changed since it was read.
+	ErrAttemptConflict = errors.New("the attempt was changed concurrently")
+)
+
+var attemptMongoNamespace = db.Namespace{Database: "praromvik", Collection: "attempts"}
+
+// Start stores the attempt as the next one of the student. If another attempt
+// took its number first, it returns ErrAttemptConflict.
+func (a *Attempt) Start() error {
+	a.ID = fmt.Sprintf("%s:%s:%d", a.UserUUID, a.Exam, a.Number)
+	a.Status = StatusInProgress
+	a.Answers = make([]assessment.Answer, 0)
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	if _, err := mongoDB.AddDocument(a); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAttemptConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// InProgress returns the attempt of the student at the exam which isn't submitted yet.
+func InProgress(userUUID string, examID string) (*Attempt, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{
+		{Key: "userUUID", Value: userUUID},
+		{Key: "exam", Value: examID},
+		{Key: "status", Value: StatusInProgress},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeAttempt(result)
+}
+
+// ListAttempts returns the attempts of the student at the exam, in order.
+func ListAttempts(userUUID string, examID string) ([]Attempt, error) {
+	return listAttempts(bson.D{{Key: "userUUID", Value: userUUID}, {Key: "exam", Value: examID}}, bson.D{{Key: "number", Value: 1}})
+}
+
+// ListAttemptsByUser returns every attempt of the student.
+func ListAttemptsByUser(userUUID string) ([]Attempt, error) {
+	return listAttempts(bson.D{{Key: "userUUID", Value: userUUID}}, bson.D{{Key: "startedAt", Value: 1}})
+}
+
+// ListExpired returns the attempts still in progress whose time, grace included, is over at now.
+func ListExpired(now time.Time) ([]Attempt, error) {
+	return listAttempts(bson.D{
+		{Key: "status", Value: StatusInProgress},
+		{Key: "deadline", Value: bson.D{{Key: "$lt", Value: now.Add(-assessment.Grace)}}},
+	}, bson.D{{Key: "deadline", Value: 1}})
+}
+
+// SaveAnswers replaces the answers of the attempt, as long as it is in progress & unchanged since it was read.
+func (a *Attempt) SaveAnswers(answers []assessment.Answer, now time.Time) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	result, err := mongoDB.FindOneAndUpdate(
+		bson.D{{Key: "_id", Value: a.ID}, {Key: "status", Value: StatusInProgress}, {Key: "revision", Value: a.Revision}},
+		nil,
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "answers", Value: answers}, {Key: "savedAt", Value: now.UTC()}}},
+			{Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	saved, err := decodeAttempt(result)
+	if errors.Is(err, ErrAttemptNotFound) {
+		return ErrAttemptConflict
+	}
+	if err != nil {
+		return err
+	}
+	*a = *saved
+	return nil
+}
+
+// Submit ends the attempt with its answers & their score. It reports false if the attempt
+// was saved or submitted since it was read.
+func (a *Attempt) Submit(score assessment.Score, now time.Time, auto bool) (bool, error) {
+	set := bson.D{
+		{Key: "status", Value: StatusSubmitted},
+		{Key: "answers", Value: a.Answers},
+		{Key: "submittedAt", Value: now.UTC()},
+		{Key: "score", Value: score},
+	}
+	if auto {
+		set = append(set, bson.E{Key: "autoSubmitted", Value: true})
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	result, err := mongoDB.FindOneAndUpdate(
+		bson.D{{Key: "_id", Value: a.ID}, {Key: "status", Value: StatusInProgress}, {Key: "revision", Value: a.Revision}},
+		nil,
+		bson.D{{Key: "$set", Value: set}, {Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	submitted, err := decodeAttempt(result)
+	if errors.Is(err, ErrAttemptNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*a = *submitted
+	return true, nil
+}
+
+// GetAttempt returns the attempt with the given id.
+func GetAttempt(id string) (*Attempt, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return nil, err
+	}
+	return decodeAttempt(result)
+}
+
+// DeleteAttemptsByUser removes every attempt of the student.
+func DeleteAttemptsByUser(userUUID string) error {
+	return deleteAttempts(bson.D{{Key: "userUUID", Value: userUUID}})
+}
+
+func deleteAttempts(filter bson.D) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	_, err := mongoDB.DeleteMany(filter)
+	return err
+}
+
+func listAttempts(filter bson.D, sort bson.D) ([]Attempt, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{attemptMongoNamespace}}
+	cursor, err := mongoDB.ListPage(filter, sort, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]Attempt, 0)
+	if err := cursor.All(context.Background(), &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func decodeAttempt(result *mongo.SingleResult) (*Attempt, error) {
+	var a Attempt
+	if err := result.Decode(&a); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAttemptNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
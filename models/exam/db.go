@@ -0,0 +1,127 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package exam
+
+import (
+	"context"
+	"errors"
+
+	"github.com/praromvik/praromvik/models/db"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrExamNotFound = errors.New("exam not found")
+
+var examMongoNamespace = db.Namespace{Database: "praromvik", Collection: "exams"}
+
+// Create stores a new exam of the course, with a new id.
+func Create(e *Exam) error {
+	e.ExamID = uuid.NewString()
+	e.QuestionCount = len(e.Questions)
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{examMongoNamespace}}
+	_, err := mongoDB.AddDocument(e)
+	return err
+}
+
+// Get returns the exam of the course with the given id.
+func Get(courseID string, examID string) (*Exam, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{examMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "_id", Value: examID}, {Key: "courseRef", Value: courseID}})
+	if err != nil {
+		return nil, err
+	}
+	var e Exam
+	if err := result.Decode(&e); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrExamNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns the exams of the course, the earliest to open first.
+func List(courseID string) ([]Exam, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{examMongoNamespace}}
+	cursor, err := mongoDB.ListPage(bson.D{{Key: "courseRef", Value: courseID}}, bson.D{{Key: "opens", Value: 1}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	exams := make([]Exam, 0)
+	if err := cursor.All(context.Background(), &exams); err != nil {
+		return nil, err
+	}
+	return exams, nil
+}
+
+// Update replaces what the exam is. Attempts already submitted keep the score they got.
+func Update(e *Exam) error {
+	e.QuestionCount = len(e.Questions)
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{examMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: e.ExamID}, {Key: "courseRef", Value: e.CourseRef}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "title", Value: e.Title},
+			{Key: "description", Value: e.Description},
+			{Key: "opens", Value: e.Opens},
+			{Key: "closes", Value: e.Closes},
+			{Key: "duration", Value: e.Duration},
+			{Key: "maxAttempts", Value: e.MaxAttempts},
+			{Key: "passPercent", Value: e.PassPercent},
+			{Key: "questionCount", Value: e.QuestionCount},
+			{Key: "questions", Value: e.Questions},
+		}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrExamNotFound
+	}
+	return nil
+}
+
+// Delete removes the exam with its attempts.
+func Delete(e *Exam) error {
+	if err := deleteAttempts(bson.D{{Key: "exam", Value: e.ExamID}}); err != nil {
+		return err
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{examMongoNamespace}}
+	_, err := mongoDB.DeleteDocument(bson.D{{Key: "_id", Value: e.ExamID}, {Key: "courseRef", Value: e.CourseRef}})
+	return err
+}
+
+// DeleteCourse removes the exams of a deleted course with their attempts.
+func DeleteCourse(courseID string) error {
+	if err := deleteAttempts(bson.D{{Key: "course", Value: courseID}}); err != nil {
+		return err
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{examMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "courseRef", Value: courseID}})
+	return err
+}
@@ -0,0 +1,47 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ledger
+
+import "time"
+
+// Entry is a certificate appended to the ledger, by the hash of its record. Hashes are hex.
+type Entry struct {
+	Seq        int64     `json:"seq" bson:"_id"` // index of the entry, from 0
+	Serial     string    `json:"serial" bson:"serial"`
+	Leaf       string    `json:"leaf" bson:"leaf"`
+	Prev       string    `json:"prev" bson:"prev"` // hash of the entry before it
+	Hash       string    `json:"hash" bson:"hash"`
+	AppendedAt time.Time `json:"appendedAt" bson:"appendedAt"`
+}
+
+// Checkpoint is a signed tree head of the ledger.
+type Checkpoint struct {
+	Size      int64     `json:"size" bson:"_id"`
+	Root      string    `json:"root" bson:"root"`
+	Head      string    `json:"head" bson:"head"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	KeyID     string    `json:"keyId" bson:"keyId"`
+	Signature []byte    `json:"signature" bson:"signature"`
+}
@@ -0,0 +1,162 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ledger
+
+import (
+	"context"
+	"errors"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/pkg/ledger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrEmpty         = errors.New("the ledger is empty")
+	ErrEntryNotFound = errors.New("not in the ledger")
+	ErrNoCheckpoint  = errors.New("the ledger has no checkpoint yet")
+	// ErrConflict means another entry took the sequence number first.
+	ErrConflict = errors.New("the ledger moved on")
+)
+
+var (
+	entryMongoNamespace      = db.Namespace{Database: "praromvik", Collection: "ledger"}
+	checkpointMongoNamespace = db.Namespace{Database: "praromvik", Collection: "ledgerCheckpoints"}
+)
+
+// Append stores the entry at its sequence number. Entries are never changed or removed afterwards.
+func Append(e *Entry) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{entryMongoNamespace}}
+	if _, err := mongoDB.AddDocument(e); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// Last returns the last entry of the ledger.
+func Last() (*Entry, error) {
+	entries, err := list(bson.D{}, -1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+	return &entries[0], nil
+}
+
+// GetBySerial returns the entry of the certificate with the serial.
+func GetBySerial(serial string) (*Entry, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{entryMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "serial", Value: serial}})
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := result.Decode(&e); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns the first size entries of the ledger, in order.
+func List(size int64) ([]Entry, error) {
+	return list(bson.D{{Key: "_id", Value: bson.D{{Key: "$lt", Value: size}}}}, 1, 0)
+}
+
+// Links returns the entries as chained.
+func Links(entries []Entry) ([]ledger.Link, error) {
+	links := make([]ledger.Link, len(entries))
+	for i, e := range entries {
+		var err error
+		if links[i].Leaf, err = ledger.ParseHash(e.Leaf); err != nil {
+			return nil, err
+		}
+		if links[i].Prev, err = ledger.ParseHash(e.Prev); err != nil {
+			return nil, err
+		}
+		if links[i].Hash, err = ledger.ParseHash(e.Hash); err != nil {
+			return nil, err
+		}
+	}
+	return links, nil
+}
+
+func AddCheckpoint(c *Checkpoint) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{checkpointMongoNamespace}}
+	_, err := mongoDB.AddDocument(c)
+	return err
+}
+
+// LatestCheckpoint returns the checkpoint of the largest ledger.
+func LatestCheckpoint() (*Checkpoint, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{checkpointMongoNamespace}}
+	cursor, err := mongoDB.ListPage(bson.D{}, bson.D{{Key: "_id", Value: -1}}, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := make([]Checkpoint, 0)
+	if err := cursor.All(context.Background(), &checkpoints); err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, ErrNoCheckpoint
+	}
+	return &checkpoints[0], nil
+}
+
+// Parse converts the checkpoint to the one signed.
+func (c *Checkpoint) Parse() (ledger.Checkpoint, error) {
+	root, err := ledger.ParseHash(c.Root)
+	if err != nil {
+		return ledger.Checkpoint{}, err
+	}
+	head, err := ledger.ParseHash(c.Head)
+	if err != nil {
+		return ledger.Checkpoint{}, err
+	}
+	return ledger.Checkpoint{Size: c.Size, Root: root, Head: head, CreatedAt: c.CreatedAt, KeyID: c.KeyID, Signature: c.Signature}, nil
+}
+
+func list(filter bson.D, order int, limit int64) ([]Entry, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{entryMongoNamespace}}
+	cursor, err := mongoDB.ListPage(filter, bson.D{{Key: "_id", Value: order}}, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0)
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
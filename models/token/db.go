@@ -0,0 +1,183 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Prefix marks a bearer credential as a personal access token.
+const Prefix = "prv_"
+
+const (
+	defaultLifetime = 30 * 24 * time.Hour
+	maxLifetime     = 365 * 24 * time.Hour
+)
+
+var tokenMongoNamespace = db.Namespace{Database: "praromvik", Collection: "tokens"}
+
+// Generate fills in the identity of a new token and returns its plaintext value.
+// Only the SHA-256 hash of the plaintext is kept, so it can't be recovered later.
+func (t *Token) Generate() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	plain := Prefix + base64.RawURLEncoding.EncodeToString(secret)
+	t.ID = uuid.NewString()
+	t.Hash = hash(plain)
+	t.CreatedAt = time.Now().UTC()
+	t.Revoked = false
+	return plain, nil
+}
+
+func (t *Token) ValidateForm() (int, error) {
+	if strings.TrimSpace(t.Name) == "" {
+		return http.StatusBadRequest, fmt.Errorf("token name is required")
+	}
+	if len(t.Scopes) == 0 {
+		return http.StatusBadRequest, fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range t.Scopes {
+		if !slices.Contains(utils.Scopes, scope) {
+			return http.StatusBadRequest, fmt.Errorf("unknown scope '%s'", scope)
+		}
+	}
+	now := time.Now()
+	if t.ExpiresAt.IsZero() {
+		t.ExpiresAt = now.Add(defaultLifetime).UTC()
+	}
+	if !t.ExpiresAt.After(now) {
+		return http.StatusBadRequest, fmt.Errorf("expiresAt must be in the future")
+	}
+	if t.ExpiresAt.After(now.Add(maxLifetime)) {
+		return http.StatusBadRequest, fmt.Errorf("tokens can't live longer than %d days", int(maxLifetime.Hours()/24))
+	}
+	return http.StatusOK, nil
+}
+
+func (t *Token) AddTokenToDB() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	_, err := mongoDB.AddDocument(t)
+	return err
+}
+
+// Revoke marks the token as revoked. The owner is part of the filter,
+// so a user can never revoke a token that belongs to somebody else.
+func (t *Token) Revoke() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: t.ID}, {Key: "userUUID", Value: t.UserUUID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked", Value: true}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("token '%s' not found", t.ID)
+	}
+	return nil
+}
+
+func (t *Token) TouchLastUsed() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	_, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: t.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "lastUsedAt", Value: time.Now().UTC()}}}},
+	)
+	return err
+}
+
+func (t *Token) Active() bool {
+	return !t.Revoked && time.Now().Before(t.ExpiresAt)
+}
+
+func (t *Token) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// GetByPlaintext looks up the token matching the given bearer credential.
+func GetByPlaintext(plain string) (*Token, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "hash", Value: hash(plain)}})
+	if err != nil {
+		return nil, err
+	}
+	var t Token
+	if err := result.Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func List(userUUID string) ([]Token, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(bson.D{{Key: "userUUID", Value: userUUID}})
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]Token, 0)
+	if err := cursor.All(context.Background(), &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RenameUser updates the owner name of the user's tokens after a user name change.
+func RenameUser(userUUID string, userName string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	_, err := mongoDB.UpdateMany(
+		bson.D{{Key: "userUUID", Value: userUUID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "userName", Value: userName}}}},
+	)
+	return err
+}
+
+// DeleteAll removes every token of the user.
+func DeleteAll(userUUID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{tokenMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "userUUID", Value: userUUID}})
+	return err
+}
+
+func hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
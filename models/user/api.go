@@ -24,16 +24,71 @@ SOFTWARE.
 
 package user
 
-import "github.com/praromvik/praromvik/models/utils"
+import (
+	"time"
+
+	"github.com/praromvik/praromvik/models/utils"
+)
 
 type User struct {
-	UserName         string       `json:"userName" bson:"userName"`
-	Certificates     []string     `json:"certificates" bson:"certificates"`
-	EnrolledCourses  []utils.Info `json:"enrolledCourses" bson:"enrolledCourses"`
-	ParticipateExams []utils.Info `json:"participateExams" bson:"participateExams"`
-	Email            string       `json:"email" bson:"email"`
-	Password         string       `json:"password" bson:"password"`
-	Phone            string       `json:"phone" bson:"phone"`
-	Role             string       `json:"role" bson:"role"`
-	UUID             string       `json:"uuid" bson:"uuid"`
+	UserName          string       `json:"userName" bson:"userName"`
+	Certificates      []string     `json:"certificates" bson:"certificates"`
+	EnrolledCourses   []utils.Info `json:"enrolledCourses" bson:"enrolledCourses" view:"self,admin,instructor"`
+	ParticipateExams  []utils.Info `json:"participateExams" bson:"participateExams" view:"self,admin,instructor"`
+	Email             string       `json:"email" bson:"email" view:"self,admin,instructor"`
+	Password          string       `json:"password" bson:"password" view:"-"`
+	Phone             string       `json:"phone" bson:"phone" view:"self,admin"`
+	EmailIndex        string       `json:"-" bson:"emailIndex,omitempty"`
+	PhoneIndex        string       `json:"-" bson:"phoneIndex,omitempty"`
+	Role              string       `json:"role" bson:"role"`
+	Roles             []RoleGrant  `json:"roles" bson:"roles" view:"self,admin"`
+	UUID              string       `json:"uuid" bson:"uuid" view:"admin"`
+	Deletion          *Deletion    `json:"deletion,omitempty" bson:"deletion,omitempty" view:"self,admin"`
+	DisplayName       string       `json:"displayName" bson:"displayName"`
+	Bio               string       `json:"bio" bson:"bio"`
+	SocialLinks       []SocialLink `json:"socialLinks" bson:"socialLinks"`
+	Avatar            string       `json:"avatar" bson:"avatar"` // id of the avatar asset
+	PreviousUserNames []string     `json:"previousUserNames" bson:"previousUserNames" view:"self,admin"`
+	PendingEmail      *EmailChange `json:"pendingEmail,omitempty" bson:"pendingEmail,omitempty" view:"self"`
+	Status            string       `json:"status,omitempty" bson:"status,omitempty" view:"self,admin"`
+	StatusReason      string       `json:"statusReason,omitempty" bson:"statusReason,omitempty" view:"self,admin"`
+	SuspendedUntil    *time.Time   `json:"suspendedUntil,omitempty" bson:"suspendedUntil,omitempty" view:"self,admin"`
+	MustResetPassword bool         `json:"mustResetPassword,omitempty" bson:"mustResetPassword,omitempty" view:"self,admin"`
+	Invitation        *Invitation  `json:"invitation,omitempty" bson:"invitation,omitempty" view:"admin"`
+}
+
+type SocialLink struct {
+	Name string `json:"name" bson:"name"`
+	URL  string `json:"url" bson:"url"`
+}
+
+// EmailChange is a new email waiting to be confirmed through the link mailed to it.
+type EmailChange struct {
+	Email     string    `json:"email" bson:"email"`
+	TokenHash string    `json:"-" bson:"tokenHash"`
+	ExpiresAt time.Time `json:"expiresAt" bson:"expiresAt"`
+}
+
+// ProfileUpdate holds the display information to change. Nil fields are left as they are.
+type ProfileUpdate struct {
+	DisplayName *string       `json:"displayName"`
+	Bio         *string       `json:"bio"`
+	SocialLinks *[]SocialLink `json:"socialLinks"`
+}
+
+// Deletion is a pending request of the user to delete their account.
+// Until ScheduledAt, the user can still sign in & cancel it.
+type Deletion struct {
+	Reason      string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	RequestedAt time.Time `json:"requestedAt" bson:"requestedAt"`
+	ScheduledAt time.Time `json:"scheduledAt" bson:"scheduledAt"`
+}
+
+// RoleGrant gives a role to a user on top of the base Role, until it expires.
+type RoleGrant struct {
+	Role      string     `json:"role" bson:"role" firestore:"role"`
+	Reason    string     `json:"reason" bson:"reason" firestore:"reason"`
+	GrantedBy string     `json:"grantedBy" bson:"grantedBy" firestore:"grantedBy"`
+	GrantedAt time.Time  `json:"grantedAt" bson:"grantedAt" firestore:"grantedAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty" firestore:"expiresAt,omitempty"`
 }
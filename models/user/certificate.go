@@ -0,0 +1,49 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AddCertificate records the serial of a certificate issued to u. It reports false if it was already recorded.
+func (u *User) AddCertificate(serial string) (bool, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UserName, Value: u.UserName}, {Key: "certificates", Value: bson.D{{Key: "$ne", Value: serial}}}},
+		// A pipeline, as the certificates of a user who never got one are null.
+		bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "certificates", Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$certificates", bson.A{}}}},
+			bson.A{bson.D{{Key: "$literal", Value: serial}}},
+		}}}}}}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
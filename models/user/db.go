@@ -31,9 +31,9 @@ import (
 
 	"github.com/praromvik/praromvik/models/db"
 	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/pii"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var userMongoNamespace = db.Namespace{Database: "praromvik", Collection: "users"}
@@ -68,14 +68,23 @@ func (u *User) VerifyLoginData() (bool, error) {
 		return false, err
 	}
 
-	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(u.Password)); err != nil {
-		return false, nil
+	if valid, err := u.verifyPassword(user.Password); err != nil || !valid {
+		return false, err
 	}
-	u.UUID, u.Role = user.UUID, user.Role
+	u.UUID, u.Role, u.Roles = user.UUID, user.Role, user.Roles
+	u.Status, u.SuspendedUntil, u.MustResetPassword = user.Status, user.SuspendedUntil, user.MustResetPassword
 	return true, nil
 }
 
 func (u *User) ValidateForm() (int, error) {
+	if err := u.CheckPassword(); err != nil {
+		return http.StatusBadRequest, err
+	}
+	for _, val := range []string{u.Email, u.Phone} {
+		if err := pii.CheckPlain(val); err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
 	keyVal := map[string]string{"userName": u.UserName, "email": u.Email, "phone": u.Phone}
 	for key, val := range keyVal {
 		if err := checkFieldAvailability(key, val); err != nil {
@@ -128,16 +137,21 @@ func (u *User) UpdateUserAuthDataToFirestore() error {
 	return err
 }
 
-func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+func (u *User) FetchAndSetUUIDFromDB() error {
+	var user User
+	dSnap, err := db.Firestore{}.GetDocument("users", u.UserName)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+	if err := dSnap.DataTo(&user); err != nil {
+		return err
+	}
+	u.UUID = user.UUID
 	return nil
 }
 
-func (u *User) FetchAndSetUUIDFromDB() error {
+// FetchAuthDataFromDB loads the uuid & roles of the user from the auth data in firestore.
+func (u *User) FetchAuthDataFromDB() error {
 	var user User
 	dSnap, err := db.Firestore{}.GetDocument("users", u.UserName)
 	if err != nil {
@@ -146,7 +160,8 @@ func (u *User) FetchAndSetUUIDFromDB() error {
 	if err := dSnap.DataTo(&user); err != nil {
 		return err
 	}
-	u.UUID = user.UUID
+	u.UUID, u.Role, u.Roles = user.UUID, user.Role, user.Roles
+	u.Status, u.SuspendedUntil, u.MustResetPassword = user.Status, user.SuspendedUntil, user.MustResetPassword
 	return nil
 }
 
@@ -162,9 +177,23 @@ func (u *User) GetFromMongo() error {
 	return nil
 }
 
+// GetByUUID fetches the user with the given uuid from mongo.
+func GetByUUID(uuid string) (*User, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: utils.UUID, Value: uuid}})
+	if err != nil {
+		return nil, err
+	}
+	var u User
+	if err := result.Decode(&u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 func checkFieldAvailability(field string, value string) error {
 	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
-	result, err := mongoDB.GetDocument(bson.D{{Key: field, Value: value}})
+	result, err := mongoDB.GetDocument(lookupFilter(field, value))
 	if err != nil {
 		return err
 	}
@@ -180,6 +209,11 @@ func getAuthData(user User) map[string]interface{} {
 		"uuid":     user.UUID,
 		"password": user.Password,
 		"role":     user.Role,
+		"roles":    user.Roles,
+		"status":   user.Status,
+		// Kept in the auth data, so that signing in doesn't need Mongo.
+		"suspendedUntil":    user.SuspendedUntil,
+		"mustResetPassword": user.MustResetPassword,
 	}
 	return authData
 }
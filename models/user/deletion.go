@@ -0,0 +1,112 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DeletionGracePeriod is how long a deletion request can be cancelled before the account is purged.
+const DeletionGracePeriod = 30 * 24 * time.Hour
+
+// RequestDeletion schedules the account for deletion after the grace period.
+func (u *User) RequestDeletion(reason string) (*Deletion, error) {
+	now := time.Now().UTC()
+	deletion := &Deletion{Reason: reason, RequestedAt: now, ScheduledAt: now.Add(DeletionGracePeriod)}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UserName, Value: u.UserName}, {Key: "deletion", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "deletion", Value: deletion}}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("the deletion of '%s' is already requested", u.UserName)
+	}
+	u.Deletion = deletion
+	return deletion, nil
+}
+
+// CancelDeletion withdraws a pending deletion request.
+func (u *User) CancelDeletion() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UserName, Value: u.UserName}, {Key: "deletion", Value: bson.D{{Key: "$exists", Value: true}}}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "deletion", Value: ""}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no deletion is pending for '%s'", u.UserName)
+	}
+	u.Deletion = nil
+	return nil
+}
+
+// PendingDeletions returns the users whose deletion is requested. With due set, only the
+// ones whose grace period is over.
+func PendingDeletions(due bool) ([]User, error) {
+	filter := bson.D{{Key: "deletion", Value: bson.D{{Key: "$exists", Value: true}}}}
+	if due {
+		filter = bson.D{{Key: "deletion.scheduledAt", Value: bson.D{{Key: "$lte", Value: time.Now().UTC()}}}}
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(filter)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, 0)
+	if err := cursor.All(context.Background(), &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// AnonymousName is the name standing in for the user in historical records once the account is purged.
+// It is stable, so records anonymized at different times still belong together.
+func (u *User) AnonymousName() string {
+	sum := sha256.Sum256([]byte(u.UUID))
+	return "deleted-" + hex.EncodeToString(sum[:6])
+}
+
+// Remove deletes the account data of the user from Mongo & Firestore.
+func (u *User) Remove() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	if _, err := mongoDB.DeleteDocument(bson.D{{Key: utils.UUID, Value: u.UUID}}); err != nil {
+		return err
+	}
+	return db.Firestore{}.DeleteDocument("users", u.UserName)
+}
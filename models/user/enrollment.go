@@ -0,0 +1,80 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"fmt"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AddEnrolledCourse records the course u is enrolled in. Name is the title of the course, UUID its id.
+func (u *User) AddEnrolledCourse(c utils.Info) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UserName, Value: u.UserName}, {Key: "enrolledCourses.uuid", Value: bson.D{{Key: "$ne", Value: c.UUID}}}},
+		// A pipeline, as the courses of a user who never enrolled are null.
+		bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "enrolledCourses", Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$enrolledCourses", bson.A{}}}},
+			bson.A{bson.D{{Key: "$literal", Value: c}}},
+		}}}}}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := mongoDB.CountDocuments(bson.D{{Key: utils.UserName, Value: u.UserName}})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("user '%s' not found", u.UserName)
+		}
+	}
+	return nil
+}
+
+// RemoveEnrolledCourse forgets the enrollment of u in the course with the given id.
+func (u *User) RemoveEnrolledCourse(courseID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	_, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UserName, Value: u.UserName}, {Key: "enrolledCourses.uuid", Value: courseID}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "enrolledCourses", Value: bson.D{{Key: "uuid", Value: courseID}}}}}},
+	)
+	return err
+}
+
+// ForgetCourse removes a deleted course from the enrolled courses of every user.
+func ForgetCourse(courseID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	_, err := mongoDB.UpdateMany(
+		bson.D{{Key: "enrolledCourses.uuid", Value: courseID}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "enrolledCourses", Value: bson.D{{Key: "uuid", Value: courseID}}}}}},
+	)
+	return err
+}
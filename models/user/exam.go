@@ -0,0 +1,56 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AddParticipatedExam records the exam u took part in, once. Name is the title of the exam, UUID its id.
+func (u *User) AddParticipatedExam(e utils.Info) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	_, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UUID, Value: u.UUID}, {Key: "participateExams.uuid", Value: bson.D{{Key: "$ne", Value: e.UUID}}}},
+		// A pipeline, as the exams of a user who never took one are null.
+		bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "participateExams", Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$participateExams", bson.A{}}}},
+			bson.A{bson.D{{Key: "$literal", Value: e}}},
+		}}}}}}}},
+	)
+	return err
+}
+
+// ForgetExam removes a deleted exam from the exams of every user.
+func ForgetExam(examID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	_, err := mongoDB.UpdateMany(
+		bson.D{{Key: "participateExams.uuid", Value: examID}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "participateExams", Value: bson.D{{Key: "uuid", Value: examID}}}}}},
+	)
+	return err
+}
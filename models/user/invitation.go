@@ -0,0 +1,114 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// invitationLifetime is how long an invited user has to choose their password.
+const invitationLifetime = 14 * 24 * time.Hour
+
+var ErrInvalidInvitation = errors.New("the invitation is invalid or has expired")
+
+// Invitation lets a user created on their behalf, e.g. by an import, choose their password.
+type Invitation struct {
+	TokenHash string    `json:"-" bson:"tokenHash"`
+	InvitedBy string    `json:"invitedBy" bson:"invitedBy"`
+	ExpiresAt time.Time `json:"expiresAt" bson:"expiresAt"`
+}
+
+// PrepareInvitation gives u a random password nobody knows, along with an invitation to choose their own,
+// and returns the token of the invitation link. u still has to be added to the database.
+func (u *User) PrepareInvitation(invitedBy string) (string, error) {
+	placeholder, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	u.Password = placeholder
+	u.Invitation = &Invitation{TokenHash: hashToken(token), InvitedBy: invitedBy, ExpiresAt: time.Now().UTC().Add(invitationLifetime)}
+	return token, nil
+}
+
+// GetInvitation returns the user the pending invitation token was issued for.
+func GetInvitation(token string) (*User, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{
+		{Key: "invitation.tokenHash", Value: hashToken(token)},
+		{Key: "invitation.expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now().UTC()}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var u User
+	if err := result.Decode(&u); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// AcceptInvitation sets the password of the user the token was issued for, and returns that user.
+func AcceptInvitation(token string, plain string) (*User, error) {
+	u, err := GetInvitation(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.SetPassword(plain); err != nil {
+		return nil, err
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	if _, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UUID, Value: u.UUID}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "invitation", Value: ""}}}},
+	); err != nil {
+		return nil, err
+	}
+	u.Invitation = nil
+	return u, nil
+}
+
+func randomToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
@@ -0,0 +1,108 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"log"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/password"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var passwordConfig = password.Config{Argon2: password.DefaultParams, Policy: password.DefaultPolicy}
+
+// UsePasswordConfig sets how passwords are hashed & which passwords are accepted.
+func UsePasswordConfig(c password.Config) {
+	passwordConfig = c
+}
+
+// CheckPassword returns why the password of u isn't acceptable, if it isn't.
+func (u *User) CheckPassword() error {
+	return passwordConfig.Policy.Check(u.Password, u.UserName)
+}
+
+func (u *User) HashPassword() error {
+	hash, err := passwordConfig.Argon2.Hash(u.Password)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
+
+// verifyPassword checks the plain text password of u against the stored hash.
+// A hash made with outdated parameters (or with bcrypt) is replaced while the plain text is at hand.
+func (u *User) verifyPassword(stored string) (bool, error) {
+	valid, err := password.Verify(stored, u.Password)
+	if err != nil || !valid {
+		return false, err
+	}
+	if passwordConfig.Argon2.NeedsRehash(stored) {
+		if err := u.rehashPassword(); err != nil {
+			log.Printf("failed to rehash the password of %s: %v", u.UserName, err)
+		}
+	}
+	return true, nil
+}
+
+func (u *User) rehashPassword() error {
+	hash, err := passwordConfig.Argon2.Hash(u.Password)
+	if err != nil {
+		return err
+	}
+	return u.storePasswordHash(hash)
+}
+
+// CheckNewPassword returns why the policy doesn't accept the plain text password for u, if it doesn't.
+func (u *User) CheckNewPassword(plain string) error {
+	return passwordConfig.Policy.Check(plain, u.UserName)
+}
+
+// SetPassword changes the password of u to the given plain text one, if the policy accepts it.
+func (u *User) SetPassword(plain string) error {
+	if err := u.CheckNewPassword(plain); err != nil {
+		return err
+	}
+	hash, err := passwordConfig.Argon2.Hash(plain)
+	if err != nil {
+		return err
+	}
+	return u.storePasswordHash(hash)
+}
+
+func (u *User) storePasswordHash(hash string) error {
+	if err := (db.Firestore{}).MergeDocument("users", u.UserName, map[string]interface{}{"password": hash}); err != nil {
+		return err
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	_, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UserName, Value: u.UserName}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "password", Value: hash}}}},
+	)
+	return err
+}
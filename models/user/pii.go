@@ -0,0 +1,209 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/pii"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// piiCipher encrypts the personal fields of users in Mongo. Without it, they are stored in plaintext.
+var piiCipher *pii.Cipher
+
+var errNoPIIKey = errors.New("the user data is encrypted, but no PII key is configured")
+
+// UsePIICipher enables the encryption of the personal fields.
+func UsePIICipher(c *pii.Cipher) {
+	piiCipher = c
+}
+
+// userDocument is User as stored in Mongo. It has none of the methods of User,
+// so that encoding it doesn't recurse into the hooks below.
+type userDocument User
+
+// MarshalBSON encrypts the email & phone, and stores their blind indexes next to them.
+func (u User) MarshalBSON() ([]byte, error) {
+	doc := userDocument(u)
+	if piiCipher != nil {
+		var err error
+		if doc.Email, doc.EmailIndex, err = protect(u.Email, u.EmailIndex); err != nil {
+			return nil, err
+		}
+		if doc.Phone, doc.PhoneIndex, err = protect(u.Phone, u.PhoneIndex); err != nil {
+			return nil, err
+		}
+		if u.PendingEmail != nil {
+			pending := *u.PendingEmail
+			if pending.Email, _, err = protect(pending.Email, ""); err != nil {
+				return nil, err
+			}
+			doc.PendingEmail = &pending
+		}
+	}
+	return bson.Marshal(doc)
+}
+
+// UnmarshalBSON decrypts the email & phone.
+func (u *User) UnmarshalBSON(data []byte) error {
+	var doc userDocument
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	var err error
+	if doc.Email, err = reveal(doc.Email); err != nil {
+		return err
+	}
+	if doc.Phone, err = reveal(doc.Phone); err != nil {
+		return err
+	}
+	if doc.PendingEmail != nil {
+		if doc.PendingEmail.Email, err = reveal(doc.PendingEmail.Email); err != nil {
+			return err
+		}
+	}
+	*u = User(doc)
+	return nil
+}
+
+// protect encrypts a value, unless it is one the cipher encrypted already. A value which only looks
+// encrypted is encrypted like any other.
+func protect(value string, index string) (string, string, error) {
+	if pii.IsEncrypted(value) {
+		if plain, err := piiCipher.Decrypt(value); err == nil {
+			if index == "" {
+				index = piiCipher.BlindIndex(plain)
+			}
+			return value, index, nil
+		}
+	}
+	encrypted, err := piiCipher.Encrypt(value)
+	if err != nil {
+		return "", "", err
+	}
+	return encrypted, piiCipher.BlindIndex(value), nil
+}
+
+func reveal(value string) (string, error) {
+	if piiCipher == nil {
+		if pii.IsEncrypted(value) {
+			return "", errNoPIIKey
+		}
+		return value, nil
+	}
+	return piiCipher.Decrypt(value)
+}
+
+// piiSet returns the $set fields storing a personal value, encrypted along with its blind index when enabled.
+func piiSet(field string, value string) (bson.D, error) {
+	if piiCipher == nil {
+		return bson.D{{Key: field, Value: value}}, nil
+	}
+	encrypted, err := piiCipher.Encrypt(value)
+	if err != nil {
+		return nil, err
+	}
+	return bson.D{{Key: field, Value: encrypted}, {Key: field + "Index", Value: piiCipher.BlindIndex(value)}}, nil
+}
+
+// lookupFilter matches users by a field. Encrypted fields are matched through their blind index,
+// and also in plaintext for the users not re-encrypted yet. A user name also matches the former names
+// of users, which stay reserved for their redirects.
+func lookupFilter(field string, value string) bson.D {
+	if field == utils.UserName {
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: utils.UserName, Value: value}},
+			bson.D{{Key: "previousUserNames", Value: value}},
+		}}}
+	}
+	index := map[string]string{"email": "emailIndex", "phone": "phoneIndex"}[field]
+	if piiCipher == nil || index == "" {
+		return bson.D{{Key: field, Value: value}}
+	}
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: index, Value: piiCipher.BlindIndex(value)}},
+		bson.D{{Key: field, Value: value}},
+	}}}
+}
+
+// rawPII is the stored form of the personal fields, as read without decryption.
+type rawPII struct {
+	UUID       string `bson:"uuid"`
+	Email      string `bson:"email"`
+	Phone      string `bson:"phone"`
+	EmailIndex string `bson:"emailIndex"`
+	PhoneIndex string `bson:"phoneIndex"`
+}
+
+// ReencryptAll encrypts the personal fields of every user with the current key, e.g. after a key rotation
+// or when encryption is enabled on existing data. It returns how many users were updated.
+func ReencryptAll() (int, error) {
+	if piiCipher == nil {
+		return 0, errors.New("no PII key is configured")
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(bson.D{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	updated := 0
+	for cursor.Next(context.Background()) {
+		var raw rawPII
+		if err := cursor.Decode(&raw); err != nil {
+			return updated, err
+		}
+		if !piiCipher.NeedsRotation(raw.Email) && !piiCipher.NeedsRotation(raw.Phone) &&
+			(raw.Email == "") == (raw.EmailIndex == "") && (raw.Phone == "") == (raw.PhoneIndex == "") {
+			continue
+		}
+		set := bson.D{}
+		for _, f := range []struct{ field, index, value string }{
+			{"email", "emailIndex", raw.Email},
+			{"phone", "phoneIndex", raw.Phone},
+		} {
+			plain, err := piiCipher.Decrypt(f.value)
+			if err != nil {
+				return updated, err
+			}
+			encrypted, err := piiCipher.Encrypt(plain)
+			if err != nil {
+				return updated, err
+			}
+			set = append(set, bson.E{Key: f.field, Value: encrypted}, bson.E{Key: f.index, Value: piiCipher.BlindIndex(plain)})
+		}
+		if _, err := mongoDB.UpdateOne(bson.D{{Key: utils.UUID, Value: raw.UUID}}, bson.D{{Key: "$set", Value: set}}); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, cursor.Err()
+}
@@ -0,0 +1,285 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/pii"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxBioLength         = 1000
+	maxSocialLinks       = 10
+
+	// emailChangeLifetime is how long the confirmation link of an email change stays valid.
+	emailChangeLifetime = 24 * time.Hour
+)
+
+var ErrInvalidEmailToken = errors.New("the email confirmation link is invalid or has expired")
+
+func (p ProfileUpdate) Validate() error {
+	if p.DisplayName != nil && utf8.RuneCountInString(*p.DisplayName) > maxDisplayNameLength {
+		return fmt.Errorf("displayName must be at most %d characters long", maxDisplayNameLength)
+	}
+	if p.Bio != nil && utf8.RuneCountInString(*p.Bio) > maxBioLength {
+		return fmt.Errorf("bio must be at most %d characters long", maxBioLength)
+	}
+	if p.SocialLinks != nil {
+		if len(*p.SocialLinks) > maxSocialLinks {
+			return fmt.Errorf("at most %d social links are allowed", maxSocialLinks)
+		}
+		for _, link := range *p.SocialLinks {
+			u, err := url.Parse(link.URL)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid social link '%s', expected an http(s) URL", link.URL)
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateProfile changes the display information of u.
+func (u *User) UpdateProfile(p ProfileUpdate) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	set := bson.D{}
+	if p.DisplayName != nil {
+		set = append(set, bson.E{Key: "displayName", Value: strings.TrimSpace(*p.DisplayName)})
+	}
+	if p.Bio != nil {
+		set = append(set, bson.E{Key: "bio", Value: *p.Bio})
+	}
+	if p.SocialLinks != nil {
+		set = append(set, bson.E{Key: "socialLinks", Value: *p.SocialLinks})
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return u.setFields(set)
+}
+
+// SetAvatar points the avatar of u to the given asset.
+func (u *User) SetAvatar(assetID string) error {
+	return u.setFields(bson.D{{Key: "avatar", Value: assetID}})
+}
+
+// CheckPhone returns why u can't change to the phone number, if it can't.
+func (u *User) CheckPhone(phone string) error {
+	if err := pii.CheckPlain(phone); err != nil {
+		return err
+	}
+	return checkFieldAvailability("phone", phone)
+}
+
+// SetPhone changes the phone number of u, if no other user has it.
+func (u *User) SetPhone(phone string) error {
+	if err := u.CheckPhone(phone); err != nil {
+		return err
+	}
+	set, err := piiSet("phone", phone)
+	if err != nil {
+		return err
+	}
+	return u.setFields(set)
+}
+
+// CheckEmail returns why u can't change to the email, if it can't.
+func (u *User) CheckEmail(email string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return fmt.Errorf("invalid email '%s'", email)
+	}
+	if err := pii.CheckPlain(email); err != nil {
+		return err
+	}
+	return checkFieldAvailability("email", email)
+}
+
+// RequestEmailChange keeps the new email aside until it is confirmed,
+// and returns the token of the confirmation link to mail to it.
+func (u *User) RequestEmailChange(email string) (string, error) {
+	if err := u.CheckEmail(email); err != nil {
+		return "", err
+	}
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	pending := EmailChange{Email: email, TokenHash: hashToken(token), ExpiresAt: time.Now().UTC().Add(emailChangeLifetime)}
+	stored := pending
+	if piiCipher != nil {
+		encrypted, err := piiCipher.Encrypt(email)
+		if err != nil {
+			return "", err
+		}
+		stored.Email = encrypted
+	}
+	if err := u.setFields(bson.D{{Key: "pendingEmail", Value: stored}}); err != nil {
+		return "", err
+	}
+	u.PendingEmail = &pending
+	return token, nil
+}
+
+// ConfirmEmailChange applies the email change the token was issued for, and returns its user.
+func ConfirmEmailChange(token string) (*User, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{
+		{Key: "pendingEmail.tokenHash", Value: hashToken(token)},
+		{Key: "pendingEmail.expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now().UTC()}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var u User
+	if err := result.Decode(&u); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+	// Somebody may have taken the email since the change was requested.
+	if err := checkFieldAvailability("email", u.PendingEmail.Email); err != nil {
+		return nil, err
+	}
+	set, err := piiSet("email", u.PendingEmail.Email)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UUID, Value: u.UUID}},
+		bson.D{{Key: "$set", Value: set}, {Key: "$unset", Value: bson.D{{Key: "pendingEmail", Value: ""}}}},
+	); err != nil {
+		return nil, err
+	}
+	u.Email, u.PendingEmail = u.PendingEmail.Email, nil
+	return &u, nil
+}
+
+// CheckUserName returns why u can't be renamed to newName, if it can't.
+func (u *User) CheckUserName(newName string) error {
+	if strings.TrimSpace(newName) == "" || strings.ContainsAny(newName, "/ ") {
+		return fmt.Errorf("invalid user name '%s'", newName)
+	}
+	// The former names of u are only reserved for u, taking one back is fine.
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	count, err := mongoDB.CountDocuments(bson.D{
+		{Key: utils.UUID, Value: bson.D{{Key: "$ne", Value: u.UUID}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: utils.UserName, Value: newName}},
+			bson.D{{Key: "previousUserNames", Value: newName}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("this userName is already in use")
+	}
+	return nil
+}
+
+// ChangeUserName renames u. The former name stays reserved & redirects to the new one.
+func (u *User) ChangeUserName(newName string) error {
+	if err := u.CheckUserName(newName); err != nil {
+		return err
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+
+	var auth User
+	dSnap, err := db.Firestore{}.GetDocument("users", u.UserName)
+	if err != nil {
+		return err
+	}
+	if err := dSnap.DataTo(&auth); err != nil {
+		return err
+	}
+	auth.UserName = newName
+	if err := (db.Firestore{}).AddDocument("users", newName, getAuthData(auth)); err != nil {
+		return err
+	}
+	filter := bson.D{{Key: utils.UUID, Value: u.UUID}}
+	if _, err := mongoDB.UpdateOne(filter, bson.D{{Key: "$pull", Value: bson.D{{Key: "previousUserNames", Value: newName}}}}); err != nil {
+		return err
+	}
+	if _, err := mongoDB.UpdateOne(filter, bson.D{
+		{Key: "$set", Value: bson.D{{Key: utils.UserName, Value: newName}}},
+		{Key: "$addToSet", Value: bson.D{{Key: "previousUserNames", Value: u.UserName}}},
+	}); err != nil {
+		return err
+	}
+	if err := (db.Firestore{}).DeleteDocument("users", u.UserName); err != nil {
+		return err
+	}
+	u.PreviousUserNames = append(u.PreviousUserNames, u.UserName)
+	u.UserName = newName
+	return nil
+}
+
+// ResolvePreviousUserName returns the current name of the user formerly known as name.
+func ResolvePreviousUserName(name string) (string, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "previousUserNames", Value: name}})
+	if err != nil {
+		return "", err
+	}
+	var u User
+	if err := result.Decode(&u); err != nil {
+		return "", err
+	}
+	return u.UserName, nil
+}
+
+func (u *User) setFields(set bson.D) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	result, err := mongoDB.UpdateOne(bson.D{{Key: utils.UUID, Value: u.UUID}}, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user '%s' not found", u.UserName)
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
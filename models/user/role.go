@@ -0,0 +1,167 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var grantableRoles = []utils.RoleType{utils.Admin, utils.Moderator, utils.Trainer, utils.Student}
+
+func ValidRole(role string) bool {
+	return slices.Contains(grantableRoles, utils.RoleType(role))
+}
+
+func (g RoleGrant) Active(now time.Time) bool {
+	return g.ExpiresAt == nil || now.Before(*g.ExpiresAt)
+}
+
+// ActiveRoles returns every role the user holds right now, along with
+// the unix time it expires at. The base role & permanent grants never expire (0).
+func (u *User) ActiveRoles(now time.Time) map[string]int64 {
+	roles := map[string]int64{}
+	if u.Role != "" {
+		roles[u.Role] = 0
+	}
+	for _, grant := range u.Roles {
+		if !grant.Active(now) {
+			continue
+		}
+		expiry := int64(0)
+		if grant.ExpiresAt != nil {
+			expiry = grant.ExpiresAt.Unix()
+		}
+		if current, ok := roles[grant.Role]; ok && (current == 0 || (expiry != 0 && expiry < current)) {
+			continue
+		}
+		roles[grant.Role] = expiry
+	}
+	return roles
+}
+
+// GrantRole adds the grant to the user, replacing an earlier grant of the same role.
+func (u *User) GrantRole(grant RoleGrant) error {
+	if err := u.GetFromMongo(); err != nil {
+		return err
+	}
+	grants := slices.DeleteFunc(slices.Clone(u.Roles), func(g RoleGrant) bool {
+		return g.Role == grant.Role
+	})
+	return u.setRoleGrants(append(grants, grant))
+}
+
+// RevokeRole removes the grant of the role. The base role can't be revoked.
+func (u *User) RevokeRole(role string) error {
+	if err := u.GetFromMongo(); err != nil {
+		return err
+	}
+	grants := slices.DeleteFunc(slices.Clone(u.Roles), func(g RoleGrant) bool {
+		return g.Role == role
+	})
+	if len(grants) == len(u.Roles) {
+		if u.Role == role {
+			return fmt.Errorf("'%s' is the base role of %s and can't be revoked", role, u.UserName)
+		}
+		return fmt.Errorf("%s doesn't hold a '%s' grant", u.UserName, role)
+	}
+	return u.setRoleGrants(grants)
+}
+
+// SetBaseRole replaces the base role of the user, and returns the one it replaced.
+func (u *User) SetBaseRole(role string) (string, error) {
+	if err := u.GetFromMongo(); err != nil {
+		return "", err
+	}
+	previous := u.Role
+	if previous == role {
+		return "", fmt.Errorf("'%s' is already the base role of %s", role, u.UserName)
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	if _, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UUID, Value: u.UUID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: utils.Role, Value: role}}}},
+	); err != nil {
+		return "", err
+	}
+	if err := (db.Firestore{}).MergeDocument("users", u.UserName, map[string]interface{}{"role": role}); err != nil {
+		return "", err
+	}
+	u.Role = role
+	return previous, nil
+}
+
+// ListByRole returns the users currently holding the role, either as base role or as an active grant.
+func ListByRole(role string) ([]User, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	cursor, err := mongoDB.ListDocuments(roleFilter(role))
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, 0)
+	if err := cursor.All(context.Background(), &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// roleFilter matches the users holding the role, as base role or as a grant not expired yet.
+func roleFilter(role string) bson.D {
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: utils.Role, Value: role}},
+		bson.D{{Key: "roles", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "role", Value: role},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "expiresAt", Value: nil}},
+				bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now()}}}},
+			}},
+		}}}}},
+	}}}
+}
+
+func (u *User) setRoleGrants(grants []RoleGrant) error {
+	if grants == nil {
+		grants = []RoleGrant{}
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	if _, err := mongoDB.UpdateOne(
+		bson.D{{Key: utils.UUID, Value: u.UUID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "roles", Value: grants}}}},
+	); err != nil {
+		return err
+	}
+	if err := (db.Firestore{}).MergeDocument("users", u.UserName, map[string]interface{}{"roles": grants}); err != nil {
+		return err
+	}
+	u.Roles = grants
+	return nil
+}
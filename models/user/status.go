@@ -0,0 +1,162 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Account statuses. An empty status is active.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+const maxPageSize = 100
+
+// EffectiveStatus returns the status of u at the given time. A suspension ends by itself at SuspendedUntil.
+func (u *User) EffectiveStatus(now time.Time) string {
+	switch u.Status {
+	case StatusBanned:
+		return StatusBanned
+	case StatusSuspended:
+		if u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil) {
+			return StatusSuspended
+		}
+	}
+	return StatusActive
+}
+
+// SetStatus moderates u. A nil until suspends for good, until reactivated.
+func (u *User) SetStatus(status string, reason string, until *time.Time) error {
+	if status != StatusActive && status != StatusSuspended && status != StatusBanned {
+		return fmt.Errorf("unknown status '%s'", status)
+	}
+	u.Status, u.StatusReason, u.SuspendedUntil = status, reason, until
+	if status != StatusSuspended {
+		u.SuspendedUntil = nil
+	}
+	return u.setModeration(bson.D{
+		{Key: "status", Value: u.Status},
+		{Key: "statusReason", Value: u.StatusReason},
+		{Key: "suspendedUntil", Value: u.SuspendedUntil},
+	}, map[string]interface{}{"status": u.Status, "suspendedUntil": u.SuspendedUntil})
+}
+
+// RequirePasswordReset makes u change their password before doing anything else.
+func (u *User) RequirePasswordReset(required bool) error {
+	u.MustResetPassword = required
+	return u.setModeration(bson.D{{Key: "mustResetPassword", Value: required}},
+		map[string]interface{}{"mustResetPassword": required})
+}
+
+func (u *User) setModeration(set bson.D, authData map[string]interface{}) error {
+	if err := u.setFields(set); err != nil {
+		return err
+	}
+	return db.Firestore{}.MergeDocument("users", u.UserName, authData)
+}
+
+// Query searches the user directory. Empty fields don't filter.
+type Query struct {
+	Name     string // part of the user name or display name, case-insensitive
+	Email    string // exact email
+	Phone    string // exact phone
+	Role     string // base role or active granted role
+	Status   string
+	Page     int64 // starting at 1
+	PageSize int64
+}
+
+// Page is one page of the user directory.
+type Page struct {
+	Users    []User `json:"users"`
+	Total    int64  `json:"total"`
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"pageSize"`
+}
+
+// Search returns the page of users matching the query, ordered by user name.
+func Search(q Query) (*Page, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 || q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+	filter := bson.D{}
+	if q.Name != "" {
+		pattern := bson.D{{Key: "$regex", Value: regexp.QuoteMeta(q.Name)}, {Key: "$options", Value: "i"}}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: utils.UserName, Value: pattern}},
+			bson.D{{Key: "displayName", Value: pattern}},
+		}})
+	}
+	// Email & phone may be encrypted, so they are matched exactly through their blind index.
+	var and bson.A
+	if q.Email != "" {
+		and = append(and, lookupFilter("email", q.Email))
+	}
+	if q.Phone != "" {
+		and = append(and, lookupFilter("phone", q.Phone))
+	}
+	if q.Role != "" {
+		and = append(and, roleFilter(q.Role))
+	}
+	if len(and) > 0 {
+		filter = append(filter, bson.E{Key: "$and", Value: and})
+	}
+	switch q.Status {
+	case "":
+	case StatusActive:
+		filter = append(filter, bson.E{Key: "status", Value: bson.D{{Key: "$nin", Value: bson.A{StatusSuspended, StatusBanned}}}})
+	default:
+		filter = append(filter, bson.E{Key: "status", Value: q.Status})
+	}
+
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{userMongoNamespace}}
+	total, err := mongoDB.CountDocuments(filter)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := mongoDB.ListPage(filter, bson.D{{Key: utils.UserName, Value: 1}}, (q.Page-1)*q.PageSize, q.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, 0)
+	if err := cursor.All(context.Background(), &users); err != nil {
+		return nil, err
+	}
+	return &Page{Users: users, Total: total, Page: q.Page, PageSize: q.PageSize}, nil
+}
@@ -39,9 +39,60 @@ const (
 // Constant for Session base Auth
 const (
 	Role          = "role"
+	Roles         = "roles"
+	CSRFToken     = "csrfToken"
+	Impersonation = "impersonation"
 	UserName      = "userName"
 	UserIP        = "userIP"
 	UserAgent     = "userAgent"
 	SessionKey    = "SESSION_KEY"
 	Authenticated = "authenticated"
 )
+
+// Scopes which can be granted to a personal access token
+const (
+	ScopeCourseRead   = "course:read"
+	ScopeCourseWrite  = "course:write"
+	ScopeLessonRead   = "lesson:read"
+	ScopeLessonWrite  = "lesson:write"
+	ScopeContentRead  = "content:read"
+	ScopeContentWrite = "content:write"
+	ScopeUserRead     = "user:read"
+)
+
+var Scopes = []string{
+	ScopeCourseRead, ScopeCourseWrite,
+	ScopeLessonRead, ScopeLessonWrite,
+	ScopeContentRead, ScopeContentWrite,
+	ScopeUserRead,
+}
+
+// Kinds of resources & actions on them, as referred by the authorization policy
+const (
+	ResourceCourse     = "course"
+	ResourceLesson     = "lesson"
+	ResourceContent    = "content"
+	ResourceSubmission = "submission"
+	ResourceUser       = "user"
+	ResourceRole       = "role"
+	ResourceProgress   = "progress"
+	ResourceExam       = "exam"
+	ResourceQuestion   = "question"
+
+	ActionRead    = "read"
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionUnlock  = "unlock"
+	ActionGrant   = "grant"
+	ActionRevoke  = "revoke"
+	ActionSetBase = "set_base"
+
+	ActionImpersonate = "impersonate"
+	ActionList        = "list"
+	ActionModerate    = "moderate"
+
+	ActionEnroll         = "enroll"
+	ActionManageStudents = "manage_students"
+	ActionAttempt        = "attempt"
+)
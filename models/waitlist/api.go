@@ -0,0 +1,49 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package waitlist
+
+import "time"
+
+// Statuses of a waitlist entry
+const (
+	StatusWaiting = "waiting"
+	StatusOffered = "offered" // a seat is reserved for the user until OfferExpiresAt
+)
+
+// Entry is a user waiting for a seat in a full course. Entries are served by JoinedAt, first come first served.
+type Entry struct {
+	ID             string     `json:"_id" bson:"_id"`
+	Course         string     `json:"course" bson:"course"`
+	UserName       string     `json:"userName" bson:"userName"`
+	JoinedAt       time.Time  `json:"joinedAt" bson:"joinedAt"`
+	Status         string     `json:"status" bson:"status"`
+	OfferedAt      *time.Time `json:"offeredAt,omitempty" bson:"offeredAt,omitempty"`
+	OfferExpiresAt *time.Time `json:"offerExpiresAt,omitempty" bson:"offerExpiresAt,omitempty"`
+}
+
+// OfferExpired reports whether the seat offered to the user can no longer be claimed at the given time.
+func (e *Entry) OfferExpired(now time.Time) bool {
+	return e.Status == StatusOffered && e.OfferExpiresAt != nil && !now.Before(*e.OfferExpiresAt)
+}
@@ -0,0 +1,218 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrAlreadyWaiting = errors.New("already on the waitlist of the course")
+	ErrNotWaiting     = errors.New("not on the waitlist of the course")
+	ErrOfferExpired   = errors.New("the seat offered from the waitlist wasn't claimed in time")
+)
+
+var waitlistMongoNamespace = db.Namespace{Database: "praromvik", Collection: "waitlist"}
+
+// Join puts the user at the end of the waitlist of the course. The id of the entry is derived from
+// the course & the user, so that joining concurrently can't queue the user twice.
+func Join(courseID string, userName string) (*Entry, error) {
+	// Entries of a renamed user keep the id they were made with.
+	if _, err := Get(courseID, userName); !errors.Is(err, ErrNotWaiting) {
+		if err == nil {
+			err = ErrAlreadyWaiting
+		}
+		return nil, err
+	}
+	e := &Entry{ID: courseID + ":" + userName, Course: courseID, UserName: userName, JoinedAt: time.Now().UTC(), Status: StatusWaiting}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	if _, err := mongoDB.AddDocument(e); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrAlreadyWaiting
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+// Get returns the entry of the user in the waitlist of the course.
+func Get(courseID string, userName string) (*Entry, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	result, err := mongoDB.GetDocument(bson.D{{Key: "course", Value: courseID}, {Key: utils.UserName, Value: userName}})
+	if err != nil {
+		return nil, err
+	}
+	return decode(result)
+}
+
+// Position returns the place of the entry in the queue, starting at 1. Entries holding an offer are out of the queue (0).
+func (e *Entry) Position() (int64, error) {
+	if e.Status != StatusWaiting {
+		return 0, nil
+	}
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	ahead, err := mongoDB.CountDocuments(bson.D{
+		{Key: "course", Value: e.Course},
+		{Key: "status", Value: StatusWaiting},
+		{Key: "joinedAt", Value: bson.D{{Key: "$lt", Value: e.JoinedAt}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return ahead + 1, nil
+}
+
+// List returns the waitlist of the course in order.
+func List(courseID string) ([]Entry, error) {
+	return list(bson.D{{Key: "course", Value: courseID}})
+}
+
+// CountWaiting returns how many users wait for a seat of the course.
+func CountWaiting(courseID string) (int64, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	return mongoDB.CountDocuments(bson.D{{Key: "course", Value: courseID}, {Key: "status", Value: StatusWaiting}})
+}
+
+// CoursesWithWaiting returns the ids of the courses somebody waits for.
+func CoursesWithWaiting() ([]string, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	values, err := mongoDB.FindDistinct("course", bson.D{{Key: "status", Value: StatusWaiting}})
+	if err != nil {
+		return nil, err
+	}
+	courses := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			courses = append(courses, id)
+		}
+	}
+	return courses, nil
+}
+
+// ExpiredOffers returns the entries whose offer wasn't claimed in time.
+func ExpiredOffers(now time.Time) ([]Entry, error) {
+	return list(bson.D{
+		{Key: "status", Value: StatusOffered},
+		{Key: "offerExpiresAt", Value: bson.D{{Key: "$lte", Value: now.UTC()}}},
+	})
+}
+
+// ListByUser returns the entries of the user in every waitlist.
+func ListByUser(userName string) ([]Entry, error) {
+	return list(bson.D{{Key: utils.UserName, Value: userName}})
+}
+
+// OfferNext makes an offer, expiring at the given time, to the first user waiting for the course.
+// It returns nil if nobody is waiting.
+func OfferNext(courseID string, expiresAt time.Time) (*Entry, error) {
+	now := time.Now().UTC()
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	result, err := mongoDB.FindOneAndUpdate(
+		bson.D{{Key: "course", Value: courseID}, {Key: "status", Value: StatusWaiting}},
+		bson.D{{Key: "joinedAt", Value: 1}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: StatusOffered},
+			{Key: "offeredAt", Value: now},
+			{Key: "offerExpiresAt", Value: expiresAt.UTC()},
+		}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decode(result)
+	if errors.Is(err, ErrNotWaiting) {
+		return nil, nil
+	}
+	return e, err
+}
+
+// Requeue withdraws the offer of the entry. It keeps its place in the queue.
+func (e *Entry) Requeue() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	_, err := mongoDB.UpdateOne(
+		bson.D{{Key: "_id", Value: e.ID}},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "status", Value: StatusWaiting}}},
+			{Key: "$unset", Value: bson.D{{Key: "offeredAt", Value: ""}, {Key: "offerExpiresAt", Value: ""}}},
+		},
+	)
+	return err
+}
+
+// Remove takes the entry off the waitlist.
+func (e *Entry) Remove() error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	_, err := mongoDB.DeleteDocument(bson.D{{Key: "_id", Value: e.ID}})
+	return err
+}
+
+// DeleteCourse empties the waitlist of a deleted course.
+func DeleteCourse(courseID string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	_, err := mongoDB.DeleteMany(bson.D{{Key: "course", Value: courseID}})
+	return err
+}
+
+// RenameUser follows a user name change.
+func RenameUser(userName string, newName string) error {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	_, err := mongoDB.UpdateMany(
+		bson.D{{Key: utils.UserName, Value: userName}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: utils.UserName, Value: newName}}}},
+	)
+	return err
+}
+
+func list(filter bson.D) ([]Entry, error) {
+	mongoDB := db.Mongo{Namespaces: []db.Namespace{waitlistMongoNamespace}}
+	cursor, err := mongoDB.ListPage(filter, bson.D{{Key: "joinedAt", Value: 1}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0)
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func decode(result *mongo.SingleResult) (*Entry, error) {
+	var e Entry
+	if err := result.Decode(&e); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotWaiting
+		}
+		return nil, err
+	}
+	return &e, nil
+}
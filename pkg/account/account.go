@@ -0,0 +1,137 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package account
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/praromvik/praromvik/models/asset"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/exam"
+	"github.com/praromvik/praromvik/models/token"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/waitlist"
+	"github.com/praromvik/praromvik/pkg/auth"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+)
+
+// Purge deletes the account of the user for good. The user is signed out everywhere, their tokens
+// & uploads are dropped, and their name is replaced with an anonymous one in courses & the audit log.
+// The account itself goes last, so that a failed purge is picked up again by the next sweep.
+func Purge(u *user.User) error {
+	anonymous := u.AnonymousName()
+	if err := auth.RevokeAllSessions(u.UUID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := token.DeleteAll(u.UUID); err != nil {
+		return fmt.Errorf("failed to delete tokens: %w", err)
+	}
+	if err := asset.DeleteAll(u.UUID); err != nil {
+		return fmt.Errorf("failed to delete assets: %w", err)
+	}
+	if err := certificate.DeleteByUser(u.UUID); err != nil {
+		return fmt.Errorf("failed to delete certificates: %w", err)
+	}
+	if err := course.DeleteProgress(u.UUID); err != nil {
+		return fmt.Errorf("failed to delete progress: %w", err)
+	}
+	if err := exam.DeleteAttemptsByUser(u.UUID); err != nil {
+		return fmt.Errorf("failed to delete exam attempts: %w", err)
+	}
+	if err := course.DeleteQuizDraws(u.UUID); err != nil {
+		return fmt.Errorf("failed to delete quizzes: %w", err)
+	}
+	if err := leaveWaitlists(u.UserName); err != nil {
+		return fmt.Errorf("failed to leave waitlists: %w", err)
+	}
+	if err := course.ReplaceMember(u.UserName, anonymous); err != nil {
+		return fmt.Errorf("failed to anonymize courses: %w", err)
+	}
+	if err := audit.Anonymize(u.UserName, anonymous); err != nil {
+		return fmt.Errorf("failed to anonymize the audit log: %w", err)
+	}
+	if err := u.Remove(); err != nil {
+		return fmt.Errorf("failed to remove the account: %w", err)
+	}
+	audit.Log(&audit.Event{Type: audit.AccountPurge, Subject: anonymous})
+	return nil
+}
+
+// PurgeDue purges every account whose grace period is over, and returns how many were purged.
+// An account failing to be purged is logged & left to the next sweep, without holding up the others.
+func PurgeDue() (int, error) {
+	users, err := user.PendingDeletions(true)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for i := range users {
+		if err := Purge(&users[i]); err != nil {
+			log.Printf("failed to purge '%s': %v", users[i].UserName, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Sweep purges the due accounts every interval until the context is done.
+func Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := PurgeDue()
+			if err != nil {
+				log.Printf("failed to purge deleted accounts: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("purged %d deleted accounts", purged)
+			}
+		}
+	}
+}
+
+// leaveWaitlists takes the user off every waitlist, passing the seats offered to them on.
+func leaveWaitlists(userName string) error {
+	entries, err := waitlist.ListByUser(userName)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := enrollment.LeaveWaitlist(e.Course, userName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package account
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/enrollment"
+	"github.com/praromvik/praromvik/pkg/mailer"
+	"github.com/praromvik/praromvik/pkg/roster"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of the rows of an import
+const (
+	RowCreated = "created"
+	RowValid   = "valid" // in a dry run
+	RowFailed  = "failed"
+)
+
+// ImportOptions tells Import what to do besides creating the accounts.
+type ImportOptions struct {
+	InvitedBy string // admin running the import
+	CourseID  string // course every imported user is enrolled in, if any
+	Invite    bool   // mail the invitations, instead of returning their links in the report
+	DryRun    bool   // only validate the rows
+	// Link returns the public URL of an API path, to build the invitation links with.
+	Link func(path string) string
+}
+
+// RowResult is what happened to one row of the roster.
+type RowResult struct {
+	Line           int    `json:"line"`
+	UserName       string `json:"userName"`
+	Email          string `json:"email"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	Enrolled       bool   `json:"enrolled,omitempty"`
+	Invited        bool   `json:"invited,omitempty"`
+	InvitationLink string `json:"invitationLink,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool        `json:"dryRun"`
+	Created int         `json:"created"`
+	Valid   int         `json:"valid"`
+	Failed  int         `json:"failed"`
+	Rows    []RowResult `json:"rows"`
+}
+
+// Import creates an account for every valid row of the roster, with the same checks as signing up.
+// Imported users choose their password through an invitation. A row failing doesn't stop the others,
+// only a course that can't be found does.
+func Import(rows []roster.Row, opts ImportOptions) (*ImportReport, error) {
+	if opts.CourseID != "" {
+		if _, err := course.GetCourse(opts.CourseID); err != nil {
+			return nil, fmt.Errorf("failed to get course '%s': %w", opts.CourseID, err)
+		}
+	}
+	report := &ImportReport{DryRun: opts.DryRun, Rows: make([]RowResult, 0, len(rows))}
+	for _, row := range rows {
+		result := importRow(row, opts)
+		switch result.Status {
+		case RowCreated:
+			report.Created++
+		case RowValid:
+			report.Valid++
+		default:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report, nil
+}
+
+func importRow(row roster.Row, opts ImportOptions) RowResult {
+	result := RowResult{Line: row.Line, UserName: row.UserName, Email: row.Email, Status: RowFailed}
+	if row.Err != nil {
+		result.Error = row.Err.Error()
+		return result
+	}
+	role := row.Role
+	if role == "" {
+		role = string(utils.Student)
+	}
+	// Admins are never created in bulk.
+	if !user.ValidRole(role) || role == string(utils.Admin) {
+		result.Error = fmt.Sprintf("role '%s' can't be imported", role)
+		return result
+	}
+
+	u := &user.User{UserName: row.UserName, Email: row.Email, Phone: row.Phone, DisplayName: row.DisplayName, Role: role}
+	token, err := u.PrepareInvitation(opts.InvitedBy)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if _, err := u.ValidateForm(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if opts.DryRun {
+		result.Status = RowValid
+		return result
+	}
+
+	u.UUID = uuid.NewString()
+	if err := u.HashPassword(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := u.AddUserDataToDB(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = RowCreated
+
+	// The account exists from here on, later failures are reported along with it.
+	if opts.CourseID != "" {
+		if _, err := enrollment.Enroll(opts.CourseID, u.UserName, time.Now()); err != nil {
+			result.Error = "failed to enroll: " + err.Error()
+		} else {
+			result.Enrolled = true
+		}
+	}
+	link := opts.Link("/api/user/invitation?token=" + url.QueryEscape(token))
+	if opts.Invite {
+		if err := sendInvitation(u, link); err != nil {
+			result.Error = "failed to send the invitation: " + err.Error()
+		} else {
+			result.Invited = true
+		}
+	}
+	if !result.Invited {
+		result.InvitationLink = link
+	}
+	return result
+}
+
+func sendInvitation(u *user.User, link string) error {
+	return mailer.Send(mailer.Message{
+		To:      u.Email,
+		Subject: "You are invited to Praromvik",
+		Body: fmt.Sprintf("Hi %s,\n\nAn account was created for you on Praromvik, with the user name %s.\n"+
+			"Open this link within 14 days to choose your password:\n%s\n", displayName(u), u.UserName, link),
+	})
+}
+
+func displayName(u *user.User) string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.UserName
+}
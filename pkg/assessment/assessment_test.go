@@ -0,0 +1,310 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package assessment
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+var questions = []Question{
+	{ID: "q1", Type: SingleChoice, Prompt: "Which keyword starts a goroutine?", Options: []string{"go", "async", "spawn"}, Correct: []int{0}},
+	{ID: "q2", Type: MultipleChoice, Prompt: "Which are reference types?", Options: []string{"map", "slice", "array", "struct"}, Correct: []int{0, 1}, Points: 2},
+	{ID: "q3", Type: ShortAnswer, Prompt: "What does `len(\"go\")` return?", Accepted: []string{"2", "two"}},
+}
+
+func TestValidateQuestions(t *testing.T) {
+	tests := []struct {
+		name      string
+		questions []Question
+		valid     bool
+	}{
+		{name: "Valid", questions: questions, valid: true},
+		{name: "Empty", questions: nil, valid: false},
+		{name: "DuplicateID", questions: []Question{questions[0], questions[0]}, valid: false},
+		{name: "SingleWithTwoCorrect", questions: []Question{{ID: "q", Type: SingleChoice, Prompt: "?", Options: []string{"a", "b"}, Correct: []int{0, 1}}}, valid: false},
+		{name: "CorrectOutOfRange", questions: []Question{{ID: "q", Type: MultipleChoice, Prompt: "?", Options: []string{"a", "b"}, Correct: []int{2}}}, valid: false},
+		{name: "ShortWithoutAccepted", questions: []Question{{ID: "q", Type: ShortAnswer, Prompt: "?"}}, valid: false},
+		{name: "UnknownType", questions: []Question{{ID: "q", Type: "essay", Prompt: "?"}}, valid: false},
+		{name: "TrueFalse", questions: []Question{{ID: "q", Type: TrueFalse, Prompt: "?", Correct: []int{1}}}, valid: true},
+		{name: "TrueFalseWithOptions", questions: []Question{{ID: "q", Type: TrueFalse, Prompt: "?", Options: []string{"yes", "no"}, Correct: []int{0}}}, valid: false},
+		{name: "CodeOutputWithoutCode", questions: []Question{{ID: "q", Type: CodeOutput, Prompt: "?", Accepted: []string{"1"}}}, valid: false},
+		{name: "UnknownDifficulty", questions: []Question{{ID: "q", Type: TrueFalse, Prompt: "?", Correct: []int{0}, Difficulty: "extreme"}}, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateQuestions(test.questions); (err == nil) != test.valid {
+				t.Fatalf("expected valid %v, got %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	saved := []Answer{{Question: "q1", Choices: []int{1}}}
+	merged, err := Merge(questions, saved, []Answer{{Question: "q1", Choices: []int{0}}, {Question: "q3", Text: "2"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(merged) != 2 || merged[0].Choices[0] != 0 || merged[1].Text != "2" {
+		t.Fatalf("expected the later answers to apply, got %+v", merged)
+	}
+	if saved[0].Choices[0] != 1 {
+		t.Fatalf("expected the saved answers to be left as they were, got %+v", saved)
+	}
+
+	invalid := []struct {
+		name   string
+		answer Answer
+	}{
+		{name: "UnknownQuestion", answer: Answer{Question: "q9", Choices: []int{0}}},
+		{name: "TwoChoicesOfSingle", answer: Answer{Question: "q1", Choices: []int{0, 1}}},
+		{name: "ChoiceOutOfRange", answer: Answer{Question: "q2", Choices: []int{4}}},
+		{name: "TextForChoice", answer: Answer{Question: "q2", Text: "map"}},
+		{name: "ChoicesForShort", answer: Answer{Question: "q3", Choices: []int{0}}},
+	}
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Merge(questions, saved, []Answer{test.answer}); err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+		})
+	}
+}
+
+func TestGrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []Answer
+		points  float64
+		percent float64
+		passed  bool
+	}{
+		{name: "Unanswered", points: 0, percent: 0, passed: false},
+		{
+			name: "AllRight",
+			answers: []Answer{
+				{Question: "q1", Choices: []int{0}},
+				{Question: "q2", Choices: []int{1, 0}},
+				{Question: "q3", Text: "  TWO "},
+			},
+			points: 4, percent: 100, passed: true,
+		},
+		{
+			name: "PartialMultipleChoice",
+			answers: []Answer{
+				{Question: "q1", Choices: []int{0}},
+				{Question: "q2", Choices: []int{0}},
+			},
+			points: 2, percent: 50, passed: true,
+		},
+		{
+			name:    "WrongChoiceCancelsRightOne",
+			answers: []Answer{{Question: "q2", Choices: []int{0, 2}}},
+			points:  0, percent: 0, passed: false,
+		},
+		{
+			name:    "EveryOptionEarnsNothing",
+			answers: []Answer{{Question: "q2", Choices: []int{0, 1, 2, 3}}},
+			points:  0, percent: 0, passed: false,
+		},
+		{
+			name:    "WrongSingleChoice",
+			answers: []Answer{{Question: "q1", Choices: []int{2}}, {Question: "q3", Text: "3"}},
+			points:  0, percent: 0, passed: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score := Grade(questions, test.answers, 50)
+			if score.Points != test.points || score.Percent != test.percent || score.Passed != test.passed {
+				t.Fatalf("expected %v points, %v%%, passed %v, got %+v", test.points, test.percent, test.passed, score)
+			}
+			if score.Max != 4 || len(score.Questions) != len(questions) {
+				t.Fatalf("expected 4 points at most over %d questions, got %+v", len(questions), score)
+			}
+		})
+	}
+}
+
+func TestGradeTrueFalseAndCodeOutput(t *testing.T) {
+	quiz := []Question{
+		{ID: "tf", Type: TrueFalse, Prompt: "Slices are comparable with ==.", Correct: []int{1}},
+		{ID: "out", Type: CodeOutput, Prompt: "What does it print?", Code: "fmt.Println(\"Go\")\nfmt.Println(1)", Accepted: []string{"Go\n1\n"}},
+	}
+	tests := []struct {
+		name    string
+		answers []Answer
+		points  float64
+	}{
+		{name: "Right", answers: []Answer{{Question: "tf", Choices: []int{1}}, {Question: "out", Text: "Go  \r\n1"}}, points: 2},
+		{name: "OutputIsCaseSensitive", answers: []Answer{{Question: "tf", Choices: []int{1}}, {Question: "out", Text: "go\n1"}}, points: 1},
+		{name: "OutputKeepsLineBreaks", answers: []Answer{{Question: "out", Text: "Go 1"}}, points: 0},
+		{name: "WrongTrueFalse", answers: []Answer{{Question: "tf", Choices: []int{0}}}, points: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if score := Grade(quiz, test.answers, 100); score.Points != test.points {
+				t.Fatalf("expected %v points, got %+v", test.points, score)
+			}
+		})
+	}
+	if _, err := Merge(quiz, nil, []Answer{{Question: "tf", Choices: []int{2}}}); err == nil {
+		t.Fatalf("expected true or false to have 2 options only")
+	}
+	if options := quiz[0].Redact().Options; !reflect.DeepEqual(options, []string{"True", "False"}) {
+		t.Fatalf("expected the options of true or false, got %v", options)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	var bank []Question
+	for i := 0; i < 10; i++ {
+		difficulty, tag := Easy, "channels"
+		if i%2 == 1 {
+			difficulty = Hard
+		}
+		if i%3 == 1 {
+			tag = "generics"
+		}
+		bank = append(bank, Question{
+			ID:         fmt.Sprintf("q%d", i),
+			Type:       SingleChoice,
+			Prompt:     "?",
+			Options:    []string{"a", "b", "c", "d"},
+			Correct:    []int{i % 4},
+			Tags:       []string{tag},
+			Difficulty: difficulty,
+		})
+	}
+	pools := []Pool{{Count: 2, Difficulty: Hard}, {Count: 3, Tags: []string{"Channels"}}}
+
+	drawn, err := Draw(bank, pools, "quiz-seed:student-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(drawn) != 5 {
+		t.Fatalf("expected 5 questions, got %d", len(drawn))
+	}
+	ids := make(map[string]bool)
+	for i, q := range drawn {
+		if ids[q.ID] {
+			t.Fatalf("expected %s to be drawn once", q.ID)
+		}
+		ids[q.ID] = true
+		if i < 2 && q.Difficulty != Hard {
+			t.Fatalf("expected a hard question first, got %+v", q)
+		}
+		if i >= 2 && q.Tags[0] != "channels" {
+			t.Fatalf("expected a question about channels, got %+v", q)
+		}
+		original := bank[slices.IndexFunc(bank, func(b Question) bool { return b.ID == q.ID })]
+		if q.Options[q.Correct[0]] != original.Options[original.Correct[0]] {
+			t.Fatalf("expected the correct option to follow the shuffle, got %+v from %+v", q, original)
+		}
+	}
+
+	reversed := slices.Clone(bank)
+	slices.Reverse(reversed)
+	again, err := Draw(reversed, pools, "quiz-seed:student-1")
+	if err != nil || !reflect.DeepEqual(drawn, again) {
+		t.Fatalf("expected the same draw whatever the order of the bank, got %+v and %+v (%v)", drawn, again, err)
+	}
+	differs := false
+	for student := 2; student < 10 && !differs; student++ {
+		other, err := Draw(bank, pools, fmt.Sprintf("quiz-seed:student-%d", student))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		differs = !reflect.DeepEqual(drawn, other)
+	}
+	if !differs {
+		t.Fatalf("expected other seeds to draw other quizzes")
+	}
+
+	if _, err := Draw(bank, []Pool{{Count: 6, Difficulty: Hard}}, "seed"); !errors.Is(err, ErrNotEnoughQuestions) {
+		t.Fatalf("expected %v, got %v", ErrNotEnoughQuestions, err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for _, q := range Redact(questions) {
+		if q.Correct != nil || q.Accepted != nil {
+			t.Fatalf("expected no answers, got %+v", q)
+		}
+	}
+	if questions[0].Correct == nil {
+		t.Fatalf("expected the questions to keep their answers")
+	}
+}
+
+func TestStart(t *testing.T) {
+	opens := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	s := Schedule{Opens: opens, Closes: opens.Add(3 * time.Hour), Duration: time.Hour, Attempts: 2}
+	tests := []struct {
+		name     string
+		now      time.Time
+		used     int
+		deadline time.Time
+		err      error
+	}{
+		{name: "BeforeOpening", now: opens.Add(-time.Minute), err: ErrNotOpen},
+		{name: "FullDuration", now: opens, deadline: opens.Add(time.Hour)},
+		{name: "CutShortByClosing", now: opens.Add(150 * time.Minute), deadline: opens.Add(3 * time.Hour)},
+		{name: "AtClosing", now: opens.Add(3 * time.Hour), err: ErrClosed},
+		{name: "NoAttemptsLeft", now: opens, used: 2, err: ErrNoAttemptsLeft},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deadline, err := s.Start(test.now, test.used)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+			if !deadline.Equal(test.deadline) {
+				t.Fatalf("expected %v, got %v", test.deadline, deadline)
+			}
+		})
+	}
+
+	unlimited := s
+	unlimited.Attempts = 0
+	if _, err := unlimited.Start(opens, 100); err != nil {
+		t.Fatalf("expected any number of attempts, got %v", err)
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	deadline := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	if !Accepts(deadline, deadline.Add(Grace)) {
+		t.Fatalf("expected answers within the grace period to count")
+	}
+	if Accepts(deadline, deadline.Add(Grace+time.Second)) {
+		t.Fatalf("expected answers after the grace period not to count")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package assessment
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+var ErrNotEnoughQuestions = errors.New("not enough questions in the bank")
+
+// Pool is a share of a quiz: Count questions drawn among those with any of the tags, or any question if none,
+// and of the difficulty if set.
+type Pool struct {
+	Count      int      `json:"count" bson:"count"`
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty" bson:"difficulty,omitempty"`
+}
+
+func (p Pool) Validate() error {
+	if p.Count <= 0 {
+		return fmt.Errorf("a pool must draw at least one question")
+	}
+	switch p.Difficulty {
+	case "", Easy, Medium, Hard:
+	default:
+		return fmt.Errorf("unknown difficulty '%s', expected %s, %s or %s", p.Difficulty, Easy, Medium, Hard)
+	}
+	return nil
+}
+
+func (p Pool) matches(q Question) bool {
+	if p.Difficulty != "" && q.Difficulty != p.Difficulty {
+		return false
+	}
+	if len(p.Tags) == 0 {
+		return true
+	}
+	for _, tag := range p.Tags {
+		if slices.ContainsFunc(q.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// Draw picks the questions of the pools from the bank, in order, with the options of choice questions
+// shuffled & their correct options following. The same bank & seed always draw the same quiz, whatever
+// order the bank is in. A question matching several pools is drawn once at most.
+func Draw(bank []Question, pools []Pool, seed string) ([]Question, error) {
+	sum := sha256.Sum256([]byte(seed))
+	random := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(sum[:8]))))
+
+	sorted := slices.Clone(bank)
+	slices.SortFunc(sorted, func(a, b Question) int { return strings.Compare(a.ID, b.ID) })
+	drawn := make([]Question, 0)
+	taken := make(map[string]bool)
+	for i, pool := range pools {
+		candidates := make([]Question, 0)
+		for _, q := range sorted {
+			if !taken[q.ID] && pool.matches(q) {
+				candidates = append(candidates, q)
+			}
+		}
+		if len(candidates) < pool.Count {
+			return nil, fmt.Errorf("%w: pool %d draws %d questions out of %d", ErrNotEnoughQuestions, i+1, pool.Count, len(candidates))
+		}
+		random.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		for _, q := range candidates[:pool.Count] {
+			taken[q.ID] = true
+			drawn = append(drawn, shuffleOptions(q, random))
+		}
+	}
+	return drawn, nil
+}
+
+// shuffleOptions reorders the options of a choice question, and its correct options along.
+func shuffleOptions(q Question, random *rand.Rand) Question {
+	if q.Type != SingleChoice && q.Type != MultipleChoice {
+		return q
+	}
+	order := random.Perm(len(q.Options))
+	options := make([]string, len(order))
+	position := make([]int, len(order))
+	for to, from := range order {
+		options[to] = q.Options[from]
+		position[from] = to
+	}
+	correct := make([]int, 0, len(q.Correct))
+	for _, c := range q.Correct {
+		correct = append(correct, position[c])
+	}
+	slices.Sort(correct)
+	q.Options, q.Correct = options, correct
+	return q
+}
@@ -0,0 +1,139 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package assessment
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// Result is the score of the answer to a question.
+type Result struct {
+	Question string  `json:"question" bson:"question"`
+	Points   float64 `json:"points" bson:"points"`
+	Max      float64 `json:"max" bson:"max"`
+}
+
+type Score struct {
+	Points    float64  `json:"points" bson:"points"`
+	Max       float64  `json:"max" bson:"max"`
+	Percent   float64  `json:"percent" bson:"percent"`
+	Passed    bool     `json:"passed" bson:"passed"`
+	Questions []Result `json:"questions,omitempty" bson:"questions"`
+}
+
+func (q Question) weight() float64 {
+	if q.Points == 0 {
+		return 1
+	}
+	return q.Points
+}
+
+// Grade scores the answers. Single choice, true or false, short & code output answers score all or nothing.
+// Multiple choice answers score the share of correct options chosen, less the share of wrong ones, never below
+// zero, so that choosing every option earns nothing. Unanswered questions score zero. Passing takes at least passPercent.
+func Grade(questions []Question, answers []Answer, passPercent float64) Score {
+	byQuestion := make(map[string]Answer, len(answers))
+	for _, a := range answers {
+		byQuestion[a.Question] = a
+	}
+	score := Score{Questions: make([]Result, 0, len(questions))}
+	for _, q := range questions {
+		result := Result{Question: q.ID, Max: q.weight()}
+		if a, ok := byQuestion[q.ID]; ok {
+			result.Points = roundPoints(q.weight() * q.credit(a))
+		}
+		score.Points += result.Points
+		score.Max += result.Max
+		score.Questions = append(score.Questions, result)
+	}
+	score.Points = roundPoints(score.Points)
+	if score.Max > 0 {
+		score.Percent = roundPoints(100 * score.Points / score.Max)
+	}
+	score.Passed = score.Percent >= passPercent
+	return score
+}
+
+// credit is the share of the points of the question the answer earns, from 0 to 1.
+func (q Question) credit(a Answer) float64 {
+	switch q.Type {
+	case SingleChoice, TrueFalse:
+		if len(a.Choices) == 1 && slices.Contains(q.Correct, a.Choices[0]) {
+			return 1
+		}
+	case MultipleChoice:
+		if len(a.Choices) == 0 {
+			return 0
+		}
+		right, wrong := 0, 0
+		for _, c := range a.Choices {
+			if slices.Contains(q.Correct, c) {
+				right++
+			} else {
+				wrong++
+			}
+		}
+		credit := float64(right) / float64(len(q.Correct))
+		if incorrect := len(q.Options) - len(q.Correct); incorrect > 0 {
+			credit -= float64(wrong) / float64(incorrect)
+		}
+		return math.Max(credit, 0)
+	case ShortAnswer, CodeOutput:
+		compare := normalize
+		if q.Type == CodeOutput {
+			compare = normalizeOutput
+		}
+		given := compare(a.Text)
+		if given == "" {
+			return 0
+		}
+		for _, accepted := range q.Accepted {
+			if compare(accepted) == given {
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+func normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// normalizeOutput only ignores the spaces ending lines & the output, as the rest of the output matters.
+func normalizeOutput(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// roundPoints keeps 2 decimals, so that partial credit adds up without floating point noise.
+func roundPoints(points float64) float64 {
+	return math.Round(points*100) / 100
+}
@@ -0,0 +1,239 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package assessment checks & grades the answers of students to questions, and keeps the time of timed attempts.
+package assessment
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of questions
+const (
+	SingleChoice   = "single"      // exactly one of the options is correct
+	MultipleChoice = "multiple"    // any number of the options are correct
+	TrueFalse      = "true_false"  // a single choice between true (0) & false (1), without options of its own
+	ShortAnswer    = "short"       // a few words, compared with the accepted answers
+	CodeOutput     = "code_output" // what the code prints, compared with the accepted outputs
+)
+
+// Difficulties of questions
+const (
+	Easy   = "easy"
+	Medium = "medium"
+	Hard   = "hard"
+)
+
+var trueFalseOptions = []string{"True", "False"}
+
+// maxAnswerLength bounds the text of a short answer, in bytes.
+const maxAnswerLength = 1000
+
+// Question is asked in an exam or a quiz. Choice questions are answered with the indexes of their options,
+// short ones with text. Correct & Accepted are never shown to students, see Redact.
+type Question struct {
+	ID         string   `json:"id" bson:"id"`
+	Type       string   `json:"type" bson:"type"`
+	Prompt     string   `json:"prompt" bson:"prompt"`
+	Code       string   `json:"code,omitempty" bson:"code,omitempty"` // the program of a code output question
+	Options    []string `json:"options,omitempty" bson:"options,omitempty"`
+	Correct    []int    `json:"correct,omitempty" bson:"correct,omitempty"`
+	Accepted   []string `json:"accepted,omitempty" bson:"accepted,omitempty"` // case & spacing are ignored, but in code output
+	Points     float64  `json:"points" bson:"points"`                         // 1 if zero
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty" bson:"difficulty,omitempty"`
+}
+
+// Answer is what a student answered to a question.
+type Answer struct {
+	Question string `json:"question" bson:"question"`
+	Choices  []int  `json:"choices,omitempty" bson:"choices,omitempty"`
+	Text     string `json:"text,omitempty" bson:"text,omitempty"`
+}
+
+func (q Question) Validate() error {
+	if q.ID == "" {
+		return fmt.Errorf("question id is required")
+	}
+	if strings.TrimSpace(q.Prompt) == "" {
+		return fmt.Errorf("question '%s' has no prompt", q.ID)
+	}
+	if q.Points < 0 {
+		return fmt.Errorf("question '%s' can't be worth negative points", q.ID)
+	}
+	switch q.Difficulty {
+	case "", Easy, Medium, Hard:
+	default:
+		return fmt.Errorf("unknown difficulty '%s' of question '%s', expected %s, %s or %s", q.Difficulty, q.ID, Easy, Medium, Hard)
+	}
+	for _, tag := range q.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("question '%s' has an empty tag", q.ID)
+		}
+	}
+	switch q.Type {
+	case TrueFalse:
+		if len(q.Options) > 0 {
+			return fmt.Errorf("question '%s' is true or false, it takes no options", q.ID)
+		}
+		if len(q.Correct) != 1 {
+			return fmt.Errorf("question '%s' needs the correct option, 0 for true or 1 for false", q.ID)
+		}
+		if err := checkChoices(q.Correct, len(trueFalseOptions)); err != nil {
+			return fmt.Errorf("question '%s': %w", q.ID, err)
+		}
+	case SingleChoice, MultipleChoice:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("question '%s' needs at least 2 options", q.ID)
+		}
+		if len(q.Correct) == 0 {
+			return fmt.Errorf("question '%s' needs a correct option", q.ID)
+		}
+		if q.Type == SingleChoice && len(q.Correct) > 1 {
+			return fmt.Errorf("question '%s' can have a single correct option", q.ID)
+		}
+		if err := checkChoices(q.Correct, len(q.Options)); err != nil {
+			return fmt.Errorf("question '%s': %w", q.ID, err)
+		}
+	case ShortAnswer, CodeOutput:
+		if len(q.Accepted) == 0 {
+			return fmt.Errorf("question '%s' needs an accepted answer", q.ID)
+		}
+		if q.Type == CodeOutput && strings.TrimSpace(q.Code) == "" {
+			return fmt.Errorf("question '%s' needs the code to run", q.ID)
+		}
+	default:
+		return fmt.Errorf("unknown type '%s' of question '%s', expected %s, %s, %s, %s or %s",
+			q.Type, q.ID, SingleChoice, MultipleChoice, TrueFalse, ShortAnswer, CodeOutput)
+	}
+	return nil
+}
+
+// ValidateQuestions checks every question, and that their ids are unique.
+func ValidateQuestions(questions []Question) error {
+	if len(questions) == 0 {
+		return fmt.Errorf("at least one question is required")
+	}
+	seen := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		if err := q.Validate(); err != nil {
+			return err
+		}
+		if seen[q.ID] {
+			return fmt.Errorf("question id '%s' is used twice", q.ID)
+		}
+		seen[q.ID] = true
+	}
+	return nil
+}
+
+// Redact removes what gives the answer away, for the question to be shown to students.
+// True or false questions get their options, for students to choose from.
+func (q Question) Redact() Question {
+	q.Options = q.options()
+	q.Correct, q.Accepted = nil, nil
+	return q
+}
+
+func (q Question) options() []string {
+	if q.Type == TrueFalse {
+		return trueFalseOptions
+	}
+	return q.Options
+}
+
+// Redact removes the answers of every question.
+func Redact(questions []Question) []Question {
+	redacted := make([]Question, 0, len(questions))
+	for _, q := range questions {
+		redacted = append(redacted, q.Redact())
+	}
+	return redacted
+}
+
+// CheckAnswer tells whether the answer fits the question, whether right or wrong.
+func (q Question) CheckAnswer(a Answer) error {
+	switch q.Type {
+	case SingleChoice, MultipleChoice, TrueFalse:
+		if a.Text != "" {
+			return fmt.Errorf("question '%s' is answered with choices", q.ID)
+		}
+		if q.Type != MultipleChoice && len(a.Choices) > 1 {
+			return fmt.Errorf("question '%s' takes a single choice", q.ID)
+		}
+		if err := checkChoices(a.Choices, len(q.options())); err != nil {
+			return fmt.Errorf("question '%s': %w", q.ID, err)
+		}
+	case ShortAnswer, CodeOutput:
+		if len(a.Choices) > 0 {
+			return fmt.Errorf("question '%s' is answered with text", q.ID)
+		}
+		if len(a.Text) > maxAnswerLength {
+			return fmt.Errorf("the answer to question '%s' is longer than %d characters", q.ID, maxAnswerLength)
+		}
+	}
+	return nil
+}
+
+// Merge checks the answers against the questions, and applies them over the saved ones.
+// A later answer to a question replaces the earlier one.
+func Merge(questions []Question, saved []Answer, answers []Answer) ([]Answer, error) {
+	byID := make(map[string]Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	merged := slices.Clone(saved)
+	for _, a := range answers {
+		q, ok := byID[a.Question]
+		if !ok {
+			return nil, fmt.Errorf("unknown question '%s'", a.Question)
+		}
+		if err := q.CheckAnswer(a); err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(merged, func(s Answer) bool { return s.Question == a.Question })
+		if i < 0 {
+			merged = append(merged, a)
+		} else {
+			merged[i] = a
+		}
+	}
+	return merged, nil
+}
+
+func checkChoices(choices []int, options int) error {
+	seen := make(map[int]bool, len(choices))
+	for _, c := range choices {
+		if c < 0 || c >= options {
+			return fmt.Errorf("option %d doesn't exist", c)
+		}
+		if seen[c] {
+			return fmt.Errorf("option %d is chosen twice", c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
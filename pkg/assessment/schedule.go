@@ -0,0 +1,89 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package assessment
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotOpen        = errors.New("the exam isn't open yet")
+	ErrClosed         = errors.New("the exam is closed")
+	ErrNoAttemptsLeft = errors.New("no attempts of the exam are left")
+)
+
+// Grace is how late answers are still accepted after the deadline of an attempt,
+// so that what was sent in the last seconds isn't lost on the way.
+const Grace = 30 * time.Second
+
+// Schedule is when & how an exam can be taken.
+type Schedule struct {
+	Opens    time.Time
+	Closes   time.Time
+	Duration time.Duration // of an attempt
+	Attempts int           // allowed per student, any number if zero
+}
+
+func (s Schedule) Validate() error {
+	if s.Opens.IsZero() || s.Closes.IsZero() {
+		return fmt.Errorf("the exam needs an opening & a closing time")
+	}
+	if !s.Closes.After(s.Opens) {
+		return fmt.Errorf("the exam must close after it opens")
+	}
+	if s.Duration <= 0 {
+		return fmt.Errorf("the duration of an attempt must be positive")
+	}
+	if s.Attempts < 0 {
+		return fmt.Errorf("the number of attempts can't be negative")
+	}
+	return nil
+}
+
+// Start checks that an attempt can start at now, after used attempts, and returns its deadline.
+// An attempt ends after the duration, or when the exam closes if that is earlier.
+func (s Schedule) Start(now time.Time, used int) (time.Time, error) {
+	if now.Before(s.Opens) {
+		return time.Time{}, ErrNotOpen
+	}
+	if !now.Before(s.Closes) {
+		return time.Time{}, ErrClosed
+	}
+	if s.Attempts > 0 && used >= s.Attempts {
+		return time.Time{}, ErrNoAttemptsLeft
+	}
+	deadline := now.Add(s.Duration)
+	if deadline.After(s.Closes) {
+		deadline = s.Closes
+	}
+	return deadline, nil
+}
+
+// Accepts tells whether answers sent at now still count for an attempt ending at deadline.
+func Accepts(deadline time.Time, now time.Time) bool {
+	return !now.After(deadline.Add(Grace))
+}
@@ -0,0 +1,107 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/praromvik/praromvik/models/utils"
+
+	"github.com/gorilla/sessions"
+)
+
+// CSRFHeader carries the synchronizer token on state-changing requests made with the session cookie.
+const CSRFHeader = "X-CSRF-Token"
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// setCSRFToken stores a fresh token in the session and hands it to the client.
+func setCSRFToken(w http.ResponseWriter, session *sessions.Session) error {
+	csrf, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+	session.Values[utils.CSRFToken] = csrf
+	w.Header().Set(CSRFHeader, csrf)
+	return nil
+}
+
+// GetCSRFToken returns the CSRF token bound to the signed-in session.
+func GetCSRFToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil {
+		return "", err
+	}
+	if csrf, ok := session.Values[utils.CSRFToken].(string); ok && csrf != "" {
+		w.Header().Set(CSRFHeader, csrf)
+		return csrf, nil
+	}
+	// Sessions created before CSRF protection get their token on first request.
+	if err := setCSRFToken(w, session); err != nil {
+		return "", err
+	}
+	if err := session.Save(r, w); err != nil {
+		return "", err
+	}
+	return session.Values[utils.CSRFToken].(string), nil
+}
+
+// VerifyCSRF reports whether a request may change state on behalf of its session cookie.
+// Requests without a signed-in session, or carrying a bearer token, have nothing to forge.
+func VerifyCSRF(r *http.Request) (bool, error) {
+	if _, ok := BearerToken(r); ok {
+		return true, nil
+	}
+	if _, err := r.Cookie(sessionTokenName); err != nil {
+		return true, nil
+	}
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil {
+		return false, err
+	}
+	return csrfMatches(session, r.Header.Get(CSRFHeader)), nil
+}
+
+// csrfMatches reports whether the given token is the one of the session. A session which isn't signed in
+// has nothing to forge.
+func csrfMatches(session *sessions.Session, given string) bool {
+	if authenticated, _ := session.Values[utils.Authenticated].(bool); session.IsNew || !authenticated {
+		return true
+	}
+	expected, _ := session.Values[utils.CSRFToken].(string)
+	if expected == "" || given == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1
+}
@@ -0,0 +1,91 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/praromvik/praromvik/models/utils"
+
+	"github.com/gorilla/sessions"
+)
+
+func TestCSRFMatches(t *testing.T) {
+	signedIn := func(csrf string) *sessions.Session {
+		session := sessions.NewSession(nil, sessionTokenName)
+		session.IsNew = false
+		session.Values[utils.Authenticated] = true
+		if csrf != "" {
+			session.Values[utils.CSRFToken] = csrf
+		}
+		return session
+	}
+	signedOut := sessions.NewSession(nil, sessionTokenName)
+	signedOut.IsNew = false
+	signedOut.Values[utils.Authenticated] = false
+
+	tests := []struct {
+		name     string
+		session  *sessions.Session
+		given    string
+		expected bool
+	}{
+		{name: "Match", session: signedIn("token-1"), given: "token-1", expected: true},
+		{name: "Mismatch", session: signedIn("token-1"), given: "token-2", expected: false},
+		{name: "Missing", session: signedIn("token-1"), given: "", expected: false},
+		{name: "NoTokenInSession", session: signedIn(""), given: "token-1", expected: false},
+		{name: "NewSession", session: sessions.NewSession(nil, sessionTokenName), given: "", expected: true},
+		{name: "SignedOut", session: signedOut, given: "", expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := csrfMatches(test.session, test.given); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestVerifyCSRFWithoutCookie(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "BearerToken", header: "Bearer prv_secret"},
+		{name: "Anonymous", header: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/course/", nil)
+			r.Header.Set("Authorization", test.header)
+			ok, err := VerifyCSRF(r)
+			if err != nil || !ok {
+				t.Fatalf("expected %v, got %v %v", true, ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+)
+
+// ImpersonationHeader is set on every response served to an impersonation session.
+const ImpersonationHeader = "X-Praromvik-Impersonating"
+
+// ImpersonationTTL is how long an impersonation lasts at most.
+const ImpersonationTTL = time.Hour
+
+var ErrAlreadyImpersonating = errors.New("the session is already impersonating a user, stop it first")
+
+// Impersonation lets an admin act as another user within the admin's own session.
+// The session still belongs to the admin, only the session getters resolve to the target.
+type Impersonation struct {
+	Admin       string           `json:"admin"`
+	Target      string           `json:"target"`
+	TargetUUID  string           `json:"-"`
+	TargetRoles map[string]int64 `json:"-"`
+	Reason      string           `json:"reason"`
+	StartedAt   time.Time        `json:"startedAt"`
+	ExpiresAt   time.Time        `json:"expiresAt"`
+}
+
+func init() {
+	gob.Register(Impersonation{})
+}
+
+func (i *Impersonation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// StartImpersonation makes the current session act as the target user.
+func StartImpersonation(w http.ResponseWriter, r *http.Request, target *user.User, reason string) (*Impersonation, error) {
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := session.Values[utils.Impersonation].(Impersonation); ok {
+		return nil, ErrAlreadyImpersonating
+	}
+	admin, _ := session.Values[utils.UserName].(string)
+	now := time.Now().UTC()
+	imp := Impersonation{
+		Admin:       admin,
+		Target:      target.UserName,
+		TargetUUID:  target.UUID,
+		TargetRoles: target.ActiveRoles(now),
+		Reason:      reason,
+		StartedAt:   now,
+		ExpiresAt:   now.Add(ImpersonationTTL),
+	}
+	session.Values[utils.Impersonation] = imp
+	if err := session.Save(r, w); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// StopImpersonation returns the session to the admin. It returns the impersonation which was stopped, if any.
+func StopImpersonation(w http.ResponseWriter, r *http.Request) (*Impersonation, error) {
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil {
+		return nil, err
+	}
+	imp, ok := session.Values[utils.Impersonation].(Impersonation)
+	if !ok {
+		return nil, nil
+	}
+	delete(session.Values, utils.Impersonation)
+	if err := session.Save(r, w); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// GetImpersonation returns the impersonation of the current session, or nil.
+func GetImpersonation(r *http.Request) (*Impersonation, error) {
+	if getTokenPrincipal(r) != nil {
+		return nil, nil
+	}
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil {
+		return nil, err
+	}
+	if imp, ok := session.Values[utils.Impersonation].(Impersonation); ok {
+		return &imp, nil
+	}
+	return nil, nil
+}
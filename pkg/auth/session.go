@@ -26,14 +26,17 @@ package auth
 
 import (
 	"context"
+	"encoding/gob"
 	"log"
 	"net/http"
 	"os"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/praromvik/praromvik/models/db/client"
 	"github.com/praromvik/praromvik/models/user"
 	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/clientip"
 
 	rstore "github.com/rbcervilla/redisstore/v8"
 )
@@ -41,15 +44,32 @@ import (
 var (
 	redisStore       *rstore.RedisStore
 	sessionTokenName = "PRAROMVIK"
+	sessionKeyPrefix string
+
+	clientIPs      = &clientip.Resolver{}
+	sessionBinding = clientip.BindStrictIP
 )
 
+// UseClientIPResolver sets how the client address is found behind proxies.
+func UseClientIPResolver(resolver *clientip.Resolver) {
+	clientIPs = resolver
+}
+
+// UseSessionBinding sets how strictly sessions are tied to the client which signed in.
+func UseSessionBinding(binding clientip.Binding) {
+	sessionBinding = binding
+}
+
 func init() {
+	gob.Register(map[string]int64{})
+
 	var err error
 	redisStore, err = rstore.NewRedisStore(context.Background(), client.Redis)
 	if err != nil {
 		log.Fatal("failed to create redis store: ", err)
 	}
-	redisStore.KeyPrefix(os.Getenv(utils.SessionKey))
+	sessionKeyPrefix = os.Getenv(utils.SessionKey)
+	redisStore.KeyPrefix(sessionKeyPrefix)
 }
 
 func StoreAuthenticated(w http.ResponseWriter, r *http.Request, u *user.User, valid bool) error {
@@ -57,17 +77,47 @@ func StoreAuthenticated(w http.ResponseWriter, r *http.Request, u *user.User, va
 	if err != nil {
 		return err
 	}
+	if !valid {
+		session.Options.MaxAge = -1
+		if uuid, ok := session.Values[utils.UUID].(string); ok && !session.IsNew {
+			if err := removeIndexedSession(uuid, session.ID); err != nil {
+				return err
+			}
+		}
+		return session.Save(r, w)
+	}
+
+	if !session.IsNew {
+		// Never reuse a session id across sign ins.
+		if err := discardSession(session); err != nil {
+			return err
+		}
+	}
 	session.Values[utils.Authenticated] = true
 	if u != nil {
 		session.Values[utils.UUID] = u.UUID
 		session.Values[utils.Role] = u.Role
+		session.Values[utils.Roles] = u.ActiveRoles(time.Now())
 		session.Values[utils.UserName] = u.UserName
 	}
-	session.Values[utils.UserIP] = getIpAddress(r)
+	session.Values[utils.UserIP] = ClientIP(r)
 	session.Values[utils.UserAgent] = r.UserAgent()
-	if !valid {
-		session.Options.MaxAge = -1
+	if err := setCSRFToken(w, session); err != nil {
+		return err
+	}
+	if err := session.Save(r, w); err != nil {
+		return err
 	}
+	return addIndexedSession(r, session)
+}
+
+// SetSessionUserName updates the user name kept in the current session after the user was renamed.
+func SetSessionUserName(w http.ResponseWriter, r *http.Request, userName string) error {
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil {
+		return err
+	}
+	session.Values[utils.UserName] = userName
 	return session.Save(r, w)
 }
 
@@ -80,16 +130,27 @@ func IsAuthenticated(r *http.Request) (bool, error) {
 	return authValue, nil
 }
 
-func GetSessionRole(r *http.Request) (utils.RoleType, error) {
+// GetSessionRoles returns the roles the signed-in user holds right now.
+// Time bound grants drop out of the session as soon as they expire.
+func GetSessionRoles(r *http.Request) ([]utils.RoleType, error) {
+	if principal := getTokenPrincipal(r); principal != nil {
+		return activeRoles(principal.roles), nil
+	}
 	session, err := redisStore.Get(r, sessionTokenName)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if imp, ok := session.Values[utils.Impersonation].(Impersonation); ok {
+		return activeRoles(imp.TargetRoles), nil
+	}
+	if roles, ok := session.Values[utils.Roles].(map[string]int64); ok {
+		return activeRoles(roles), nil
 	}
 	role, ok := session.Values[utils.Role]
 	if !ok || role == nil {
-		return utils.None, nil
+		return []utils.RoleType{utils.None}, nil
 	}
-	return utils.RoleType(role.(string)), nil
+	return []utils.RoleType{utils.RoleType(role.(string))}, nil
 }
 
 func SessionValid(r *http.Request) (bool, error) {
@@ -97,19 +158,42 @@ func SessionValid(r *http.Request) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return !session.IsNew &&
-		session.Values[utils.UserIP] == getIpAddress(r) &&
-		session.Values[utils.UserAgent] == r.UserAgent(), nil
+	if session.IsNew {
+		return false, nil
+	}
+	boundIP, _ := session.Values[utils.UserIP].(string)
+	boundUserAgent, _ := session.Values[utils.UserAgent].(string)
+	return sessionBinding.Matches(boundIP, boundUserAgent, ClientIP(r), r.UserAgent()), nil
 }
 
 func GetUserInfoFromSession(r *http.Request) (*utils.Info, error) {
+	if principal := getTokenPrincipal(r); principal != nil {
+		info := principal.info
+		return &info, nil
+	}
 	session, err := redisStore.Get(r, sessionTokenName)
 	if err != nil {
 		return nil, err
 	}
+	if imp, ok := session.Values[utils.Impersonation].(Impersonation); ok {
+		return &utils.Info{Name: imp.Target, UUID: imp.TargetUUID}, nil
+	}
 	return &utils.Info{Name: session.Values[utils.UserName].(string), UUID: session.Values[utils.UUID].(string)}, nil
 }
 
-func getIpAddress(r *http.Request) string {
-	return strings.Split(r.RemoteAddr, ":")[0]
+// ClientIP returns the address of the client, honoring forwarding headers set by trusted proxies.
+func ClientIP(r *http.Request) string {
+	return clientIPs.ClientIP(r)
+}
+
+func activeRoles(expiries map[string]int64) []utils.RoleType {
+	now := time.Now().Unix()
+	roles := make([]utils.RoleType, 0, len(expiries))
+	for role, expiry := range expiries {
+		if expiry == 0 || now < expiry {
+			roles = append(roles, utils.RoleType(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+	return roles
 }
@@ -0,0 +1,58 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+)
+
+func TestActiveRoles(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	u := &user.User{Role: "student", Roles: []user.RoleGrant{
+		{Role: "moderator", ExpiresAt: &later},
+		{Role: "trainer", ExpiresAt: &earlier},
+		{Role: "instructor"},
+	}}
+	expiries := u.ActiveRoles(now)
+	if _, ok := expiries["trainer"]; ok {
+		t.Fatalf("expected the expired grant to be left out, got %v", expiries)
+	}
+	if expiries["moderator"] != later.Unix() || expiries["instructor"] != 0 || expiries["student"] != 0 {
+		t.Fatalf("expected the base role & the grants with their expiries, got %v", expiries)
+	}
+
+	// A grant expiring while the session lives stops counting right away.
+	expiries["trainer"] = earlier.Unix()
+	expected := []utils.RoleType{"instructor", "moderator", "student"}
+	if got := activeRoles(expiries); !slices.Equal(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/models/utils"
+
+	"github.com/gorilla/sessions"
+)
+
+// SessionInfo describes one signed-in device of a user.
+// ID is derived from the session id, so the cookie value itself is never exposed.
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Current   bool      `json:"current"`
+}
+
+// ListSessions returns the live sessions of the user, most recently used first.
+func ListSessions(r *http.Request, uuid string) ([]SessionInfo, error) {
+	ids, err := db.Redis{}.SetMembers(userSessionsKey(uuid))
+	if err != nil {
+		return nil, err
+	}
+	current := ""
+	if session, err := redisStore.Get(r, sessionTokenName); err == nil && !session.IsNew {
+		current = session.ID
+	}
+
+	infos := make([]SessionInfo, 0, len(ids))
+	for _, id := range ids {
+		alive, err := db.Redis{}.Exists(sessionKeyPrefix + id)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := db.Redis{}.GetHash(sessionMetaKey(id))
+		if err != nil {
+			return nil, err
+		}
+		if !alive || meta[utils.UUID] != uuid {
+			// The session has expired or was taken over by another sign in.
+			if err := removeIndexedSession(uuid, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		infos = append(infos, SessionInfo{
+			ID:        sessionHandle(id),
+			Device:    meta[utils.UserAgent],
+			IP:        meta[utils.UserIP],
+			CreatedAt: parseUnix(meta[createdAtField]),
+			LastSeen:  parseUnix(meta[lastSeenField]),
+			Current:   id == current,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastSeen.After(infos[j].LastSeen)
+	})
+	return infos, nil
+}
+
+// RevokeSession signs the user out of the session with the given handle.
+func RevokeSession(uuid string, handle string) error {
+	ids, err := db.Redis{}.SetMembers(userSessionsKey(uuid))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if sessionHandle(id) == handle {
+			return revokeSession(uuid, id)
+		}
+	}
+	return fmt.Errorf("session '%s' not found", handle)
+}
+
+// RevokeAllSessions signs the user out everywhere.
+func RevokeAllSessions(uuid string) error {
+	ids, err := db.Redis{}.SetMembers(userSessionsKey(uuid))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := revokeSession(uuid, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere but in the current session.
+func RevokeOtherSessions(r *http.Request, uuid string) error {
+	current := ""
+	if session, err := redisStore.Get(r, sessionTokenName); err == nil && !session.IsNew {
+		current = session.ID
+	}
+	ids, err := db.Redis{}.SetMembers(userSessionsKey(uuid))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if id == current {
+			continue
+		}
+		if err := revokeSession(uuid, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TouchSession records the current request as the last activity of the session.
+func TouchSession(r *http.Request) error {
+	session, err := redisStore.Get(r, sessionTokenName)
+	if err != nil || session.IsNew {
+		return err
+	}
+	return db.Redis{}.SetHash(sessionMetaKey(session.ID), map[string]interface{}{
+		utils.UserIP:  ClientIP(r),
+		lastSeenField: time.Now().Unix(),
+	})
+}
+
+func addIndexedSession(r *http.Request, session *sessions.Session) error {
+	uuid, _ := session.Values[utils.UUID].(string)
+	if uuid == "" {
+		return nil
+	}
+	now := time.Now().Unix()
+	metaKey := sessionMetaKey(session.ID)
+	if err := (db.Redis{}).SetHash(metaKey, map[string]interface{}{
+		utils.UUID:      uuid,
+		utils.UserAgent: r.UserAgent(),
+		utils.UserIP:    ClientIP(r),
+		createdAtField:  now,
+		lastSeenField:   now,
+	}); err != nil {
+		return err
+	}
+	if err := (db.Redis{}).Expire(metaKey, time.Duration(session.Options.MaxAge)*time.Second); err != nil {
+		return err
+	}
+	return db.Redis{}.AddToSet(userSessionsKey(uuid), session.ID)
+}
+
+func removeIndexedSession(uuid string, id string) error {
+	if err := (db.Redis{}).RemoveFromSet(userSessionsKey(uuid), id); err != nil {
+		return err
+	}
+	return db.Redis{}.Delete(sessionMetaKey(id))
+}
+
+func revokeSession(uuid string, id string) error {
+	if err := (db.Redis{}).Delete(sessionKeyPrefix + id); err != nil {
+		return err
+	}
+	return removeIndexedSession(uuid, id)
+}
+
+// discardSession drops the stored state of the session and clears its id,
+// so that the next save issues a fresh one.
+func discardSession(session *sessions.Session) error {
+	if uuid, ok := session.Values[utils.UUID].(string); ok {
+		if err := revokeSession(uuid, session.ID); err != nil {
+			return err
+		}
+	} else if err := (db.Redis{}).Delete(sessionKeyPrefix + session.ID); err != nil {
+		return err
+	}
+	session.ID = ""
+	return nil
+}
+
+const (
+	createdAtField = "createdAt"
+	lastSeenField  = "lastSeen"
+)
+
+func userSessionsKey(uuid string) string {
+	return sessionKeyPrefix + "sessions:" + uuid
+}
+
+func sessionMetaKey(id string) string {
+	return sessionKeyPrefix + "session-meta:" + id
+}
+
+func sessionHandle(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+func parseUnix(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
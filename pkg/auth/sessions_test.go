@@ -0,0 +1,64 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionHandle(t *testing.T) {
+	id := "MTcxNDU2Nzg5MHxEdi1CQkFFQ180SUFBUkFCRUFBQV9"
+	handle := sessionHandle(id)
+	if handle != sessionHandle(id) {
+		t.Fatalf("expected the handle of a session to be stable")
+	}
+	if handle == sessionHandle(id+"x") {
+		t.Fatalf("expected sessions to get distinct handles")
+	}
+	if len(handle) != 32 || strings.Contains(handle, id) {
+		t.Fatalf("expected a 32 character hash hiding the session id, got %v", handle)
+	}
+}
+
+func TestParseUnix(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+	}{
+		{name: "Seconds", value: "1714567890", expected: time.Unix(1714567890, 0).UTC()},
+		{name: "Empty", value: "", expected: time.Time{}},
+		{name: "Malformed", value: "yesterday", expected: time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseUnix(test.value); !got.Equal(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
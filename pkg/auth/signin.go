@@ -0,0 +1,150 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/db"
+	"github.com/praromvik/praromvik/pkg/lockout"
+)
+
+var (
+	userSignInPolicy = lockout.Policy{
+		FreeAttempts: 5,
+		BaseLockout:  30 * time.Second,
+		MaxLockout:   time.Hour,
+		Window:       24 * time.Hour,
+	}
+	ipSignInPolicy = lockout.Policy{
+		FreeAttempts: 20,
+		BaseLockout:  30 * time.Second,
+		MaxLockout:   time.Hour,
+		Window:       time.Hour,
+	}
+	// An address failing against this many distinct usernames looks like credential stuffing.
+	suspiciousUserNamesPerIP int64 = 10
+)
+
+// SignInRetryAfter returns how long the user or the client address is locked out of signing in.
+func SignInRetryAfter(userName string, r *http.Request) (time.Duration, error) {
+	userLock, err := db.Redis{}.TTL(signInKey("lock:user", userName))
+	if err != nil {
+		return 0, err
+	}
+	ipLock, err := db.Redis{}.TTL(signInKey("lock:ip", ClientIP(r)))
+	if err != nil {
+		return 0, err
+	}
+	return max(userLock, ipLock), nil
+}
+
+// RecordSignInFailure counts a failed sign in against both the username and the client address,
+// and locks them out once they run out of free attempts.
+func RecordSignInFailure(userName string, r *http.Request) error {
+	ip := ClientIP(r)
+	if err := countSignInFailure("user", userName, userSignInPolicy, r); err != nil {
+		return err
+	}
+	if err := countSignInFailure("ip", ip, ipSignInPolicy, r); err != nil {
+		return err
+	}
+
+	attemptedKey := signInKey("usernames:ip", ip)
+	if err := (db.Redis{}).AddToSet(attemptedKey, strings.ToLower(userName)); err != nil {
+		return err
+	}
+	if err := (db.Redis{}).Expire(attemptedKey, ipSignInPolicy.Window); err != nil {
+		return err
+	}
+	attempted, err := db.Redis{}.SetSize(attemptedKey)
+	if err != nil {
+		return err
+	}
+	if attempted >= suspiciousUserNamesPerIP {
+		// Only report it once per window.
+		first, err := db.Redis{}.SetIfAbsent(signInKey("reported:ip", ip), 1, ipSignInPolicy.Window)
+		if err != nil {
+			return err
+		}
+		if first {
+			audit.Log(&audit.Event{
+				Type:      audit.SignInCredentialStuffing,
+				IP:        ip,
+				UserAgent: r.UserAgent(),
+				Details:   map[string]string{"distinctUserNames": fmt.Sprint(attempted)},
+			})
+		}
+	}
+	return nil
+}
+
+// ResetSignInFailures forgets the failures of the user after a successful sign in.
+func ResetSignInFailures(userName string) error {
+	return db.Redis{}.Delete(signInKey("failures:user", userName))
+}
+
+// UnlockSignIn lifts the lockout of the user.
+func UnlockSignIn(userName string) error {
+	return db.Redis{}.Delete(signInKey("failures:user", userName), signInKey("lock:user", userName))
+}
+
+func countSignInFailure(kind string, value string, policy lockout.Policy, r *http.Request) error {
+	failures, err := db.Redis{}.Increment(signInKey("failures:"+kind, value), policy.Window)
+	if err != nil {
+		return err
+	}
+	// Keep remembering the failures as long as they keep coming.
+	if err := (db.Redis{}).Expire(signInKey("failures:"+kind, value), policy.Window); err != nil {
+		return err
+	}
+	duration := policy.Lockout(failures)
+	if duration == 0 {
+		return nil
+	}
+	if err := (db.Redis{}).Set(signInKey("lock:"+kind, value), failures, duration); err != nil {
+		return err
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.SignInLockout,
+		Subject:   value,
+		IP:        ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details: map[string]string{
+			"kind":     kind,
+			"failures": fmt.Sprint(failures),
+			"lockout":  duration.String(),
+		},
+	})
+	return nil
+}
+
+func signInKey(kind string, value string) string {
+	return fmt.Sprintf("%ssignin:%s:%s", sessionKeyPrefix, kind, strings.ToLower(value))
+}
@@ -0,0 +1,83 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"time"
+
+	"github.com/praromvik/praromvik/models/db"
+)
+
+// The moderation state of users is mirrored in redis, so that every request can be checked cheaply.
+// Mongo & Firestore stay the source of truth, signing in restores the mirror.
+
+// SetUserStatus records a suspension or ban of the user. A suspension with an end expires by itself.
+// Any other status lifts the restriction.
+func SetUserStatus(uuid string, status string, until *time.Time) error {
+	key := userStatusKey(uuid)
+	switch status {
+	case "suspended", "banned":
+		var ttl time.Duration
+		if until != nil {
+			ttl = time.Until(*until)
+			if ttl <= 0 {
+				return db.Redis{}.Delete(key)
+			}
+		}
+		return db.Redis{}.Set(key, status, ttl)
+	default:
+		return db.Redis{}.Delete(key)
+	}
+}
+
+// RequirePasswordReset records whether the user has to change their password before anything else.
+func RequirePasswordReset(uuid string, required bool) error {
+	if !required {
+		return db.Redis{}.Delete(passwordResetKey(uuid))
+	}
+	return db.Redis{}.Set(passwordResetKey(uuid), "1", 0)
+}
+
+// UserRestrictions returns the suspension or ban of the user (empty if none),
+// and whether they have to reset their password.
+func UserRestrictions(uuid string) (string, bool, error) {
+	status, err := db.Redis{}.Get(userStatusKey(uuid))
+	if err != nil {
+		return "", false, err
+	}
+	reset, err := db.Redis{}.Exists(passwordResetKey(uuid))
+	if err != nil {
+		return "", false, err
+	}
+	return status, reset, nil
+}
+
+func userStatusKey(uuid string) string {
+	return sessionKeyPrefix + "user-status:" + uuid
+}
+
+func passwordResetKey(uuid string) string {
+	return sessionKeyPrefix + "password-reset:" + uuid
+}
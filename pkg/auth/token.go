@@ -0,0 +1,109 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/praromvik/praromvik/models/token"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+)
+
+type tokenContextKey struct{}
+
+// tokenPrincipal is the identity behind a request authenticated with a personal access token.
+type tokenPrincipal struct {
+	info  utils.Info
+	roles map[string]int64
+	token *token.Token
+}
+
+// BearerToken returns the personal access token carried by the Authorization header, if any.
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, value, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, token.Prefix) {
+		return "", false
+	}
+	return value, true
+}
+
+// AuthenticateToken validates a personal access token and returns a request
+// carrying the token owner's identity, so that the session getters resolve to it.
+func AuthenticateToken(r *http.Request, plain string) (*http.Request, error) {
+	t, err := token.GetByPlaintext(plain)
+	if err != nil {
+		return nil, fmt.Errorf("unknown token")
+	}
+	if !t.Active() {
+		return nil, fmt.Errorf("token is expired or revoked")
+	}
+	u := &user.User{UserName: t.UserName}
+	if err := u.FetchAuthDataFromDB(); err != nil {
+		return nil, err
+	}
+	if u.UUID != t.UserUUID {
+		return nil, fmt.Errorf("token owner no longer exists")
+	}
+	if err := t.TouchLastUsed(); err != nil {
+		log.Printf("failed to update token last used time: %v", err)
+	}
+	principal := &tokenPrincipal{
+		info:  utils.Info{Name: u.UserName, UUID: u.UUID},
+		roles: u.ActiveRoles(time.Now()),
+		token: t,
+	}
+	return r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, principal)), nil
+}
+
+// IsTokenRequest reports whether the request was authenticated with a personal access token.
+func IsTokenRequest(r *http.Request) bool {
+	return getTokenPrincipal(r) != nil
+}
+
+// HasScope reports whether the request may act on the given scope.
+// Scopes only narrow tokens; session requests are never restricted by them.
+func HasScope(r *http.Request, scope string) bool {
+	principal := getTokenPrincipal(r)
+	if principal == nil {
+		return true
+	}
+	return principal.token.HasScope(scope)
+}
+
+func getTokenPrincipal(r *http.Request) *tokenPrincipal {
+	principal, _ := r.Context().Value(tokenContextKey{}).(*tokenPrincipal)
+	return principal
+}
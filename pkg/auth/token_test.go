@@ -0,0 +1,78 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/praromvik/praromvik/models/token"
+	"github.com/praromvik/praromvik/models/utils"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+		found    bool
+	}{
+		{name: "Token", header: "Bearer prv_secret", expected: "prv_secret", found: true},
+		{name: "LowerCaseScheme", header: "bearer prv_secret", expected: "prv_secret", found: true},
+		{name: "None", header: "", found: false},
+		{name: "OtherScheme", header: "Basic prv_secret", found: false},
+		{name: "NotAPersonalToken", header: "Bearer eyJhbGciOiJIUzI1NiJ9", found: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/course/list", nil)
+			r.Header.Set("Authorization", test.header)
+			got, found := BearerToken(r)
+			if found != test.found || got != test.expected {
+				t.Fatalf("expected %v %v, got %v %v", test.expected, test.found, got, found)
+			}
+		})
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	session := httptest.NewRequest("POST", "/api/course/", nil)
+	principal := &tokenPrincipal{token: &token.Token{Scopes: []string{utils.ScopeCourseRead}}}
+	withToken := session.WithContext(context.WithValue(session.Context(), tokenContextKey{}, principal))
+
+	if !HasScope(session, utils.ScopeCourseWrite) {
+		t.Fatalf("expected session requests not to be restricted by scopes")
+	}
+	if !HasScope(withToken, utils.ScopeCourseRead) {
+		t.Fatalf("expected the token to hold its scope")
+	}
+	if HasScope(withToken, utils.ScopeCourseWrite) {
+		t.Fatalf("expected the token to lack a scope it wasn't granted")
+	}
+	if !IsTokenRequest(withToken) || IsTokenRequest(session) {
+		t.Fatalf("expected only the token request to be told apart")
+	}
+}
@@ -0,0 +1,181 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/praromvik/praromvik/pkg/ledger"
+)
+
+func TestRender(t *testing.T) {
+	tmpl, err := LoadTemplate("")
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	out, err := tmpl.Render(Data{
+		Serial:      "7XQ2-M9KD-3HVA-P0TE",
+		Name:        "Jane Doe",
+		Course:      "Advanced Golang",
+		Instructors: []string{"arnob", "sunny"},
+		IssuedAt:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		VerifyURL:   "https://praromvik.com/verify/7XQ2-M9KD-3HVA-P0TE",
+	})
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	for _, expected := range []string{
+		"(Jane Doe) Tj",
+		"(Advanced Golang) Tj",
+		"(Instructed by arnob, sunny) Tj",
+		"(Issued on May 1, 2024) Tj",
+		"(Serial 7XQ2-M9KD-3HVA-P0TE) Tj",
+		"(Verify at https://praromvik.com/verify/7XQ2-M9KD-3HVA-P0TE) Tj",
+	} {
+		if !bytes.Contains(out, []byte(expected)) {
+			t.Fatalf("expected %q in the certificate", expected)
+		}
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		valid    bool
+	}{
+		{name: "Valid", template: `{"width": 600, "height": 400, "texts": [{"text": "{{.Name}}", "y": 200, "size": 20, "align": "center"}]}`, valid: true},
+		{name: "NoPageSize", template: `{"texts": []}`, valid: false},
+		{name: "UnknownField", template: `{"width": 600, "height": 400, "texts": [{"text": "{{.Grade}}", "y": 200, "size": 20}]}`, valid: false},
+		{name: "BadSyntax", template: `{"width": 600, "height": 400, "texts": [{"text": "{{.Name", "y": 200, "size": 20}]}`, valid: false},
+		{name: "UnknownAlignment", template: `{"width": 600, "height": 400, "texts": [{"text": "x", "y": 200, "size": 20, "align": "justify"}]}`, valid: false},
+		{name: "NoSize", template: `{"width": 600, "height": 400, "texts": [{"text": "x", "y": 200}]}`, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "template.json")
+			if err := os.WriteFile(path, []byte(test.template), 0o600); err != nil {
+				t.Fatalf("expected %v, got %v", nil, err)
+			}
+			_, err := LoadTemplate(path)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestSerial(t *testing.T) {
+	serial, err := NewSerial()
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	if !regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{4}(-[0-9A-HJKMNP-TV-Z]{4}){3}$`).MatchString(serial) {
+		t.Fatalf("expected a serial, got %v", serial)
+	}
+
+	tests := []struct {
+		name     string
+		serial   string
+		expected string
+	}{
+		{name: "AsIs", serial: "7XQ2-M9KD-3HVA-P0TE", expected: "7XQ2-M9KD-3HVA-P0TE"},
+		{name: "LowerCase", serial: " 7xq2-m9kd-3hva-p0te ", expected: "7XQ2-M9KD-3HVA-P0TE"},
+		{name: "AmbiguousLetters", serial: "7XQ2-M9KD-3HVA-POTE", expected: "7XQ2-M9KD-3HVA-P0TE"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NormalizeSerial(test.serial); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestProof(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	document := []byte("%PDF-1.4 certificate")
+	sum := sha256.Sum256(document)
+	record := Record{
+		Serial:      "7XQ2-M9KD-3HVA-P0TE",
+		Name:        "Jane Doe",
+		Course:      "advanced-golang",
+		CourseTitle: "Advanced Golang",
+		IssuedAt:    "2024-05-01T10:00:00Z",
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	leaves := []ledger.Hash{ledger.LeafHash([]byte("a")), record.Leaf(), ledger.LeafHash([]byte("b"))}
+	checkpoint := ledger.Checkpoint{Size: 3, Root: ledger.Root(leaves), Head: leaves[2], CreatedAt: time.Now()}
+	checkpoint.Sign(private)
+	path, err := ledger.InclusionProof(leaves, 1)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+
+	// The proof survives its trip through JSON.
+	data, err := json.MarshalIndent(Proof{Certificate: record, Ledger: ledger.Proof{LeafIndex: 1, AuditPath: path, Checkpoint: checkpoint}}, "", "  ")
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	var proof Proof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	renamed := proof
+	renamed.Certificate.Name = "John Doe"
+
+	tests := []struct {
+		name     string
+		proof    Proof
+		document []byte
+		valid    bool
+	}{
+		{name: "Valid", proof: proof, valid: true},
+		{name: "ValidWithDocument", proof: proof, document: document, valid: true},
+		{name: "OtherDocument", proof: proof, document: []byte("%PDF-1.4 forged"), valid: false},
+		{name: "AlteredCertificate", proof: renamed, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.proof.Verify(public, test.document)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certificate
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/praromvik/praromvik/pkg/ledger"
+)
+
+// Record is what a certificate says, as entered in the ledger. The ledger only keeps its hash.
+type Record struct {
+	Serial      string   `json:"serial"`
+	Name        string   `json:"name"`
+	Course      string   `json:"course"`
+	CourseTitle string   `json:"courseTitle"`
+	Instructors []string `json:"instructors"`
+	IssuedAt    string   `json:"issuedAt"` // RFC 3339, in seconds
+	SHA256      string   `json:"sha256"`   // of the PDF
+}
+
+// Leaf hashes the record the same way however it was transported.
+func (r Record) Leaf() ledger.Hash {
+	if r.Instructors == nil {
+		r.Instructors = []string{}
+	}
+	data, _ := json.Marshal(r)
+	return ledger.LeafHash(data)
+}
+
+// Proof shows that a certificate is in the ledger. It is checked offline with the public key of the ledger.
+type Proof struct {
+	Certificate Record       `json:"certificate"`
+	Ledger      ledger.Proof `json:"ledger"`
+}
+
+// Verify checks the proof against the public key of the ledger and, if given, that document is the PDF of the certificate.
+func (p Proof) Verify(key ed25519.PublicKey, document []byte) error {
+	if err := p.Ledger.Verify(p.Certificate.Leaf(), key); err != nil {
+		return err
+	}
+	if document != nil {
+		sum := sha256.Sum256(document)
+		if hex.EncodeToString(sum[:]) != p.Certificate.SHA256 {
+			return fmt.Errorf("the document isn't the PDF of the certificate %s", p.Certificate.Serial)
+		}
+	}
+	return nil
+}
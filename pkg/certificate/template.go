@@ -0,0 +1,225 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/praromvik/praromvik/pkg/pdf"
+)
+
+// Text alignments, relative to X
+const (
+	Left   = "left"
+	Center = "center" // on X, or on the middle of the page if X is 0
+	Right  = "right"
+)
+
+// margin keeps texts this far from the edges of the page, shrinking the ones too long to fit.
+const margin = 60
+
+//go:embed default_template.json
+var defaultTemplate []byte
+
+// Data is what a certificate says.
+type Data struct {
+	Serial      string
+	Name        string
+	Course      string // title of the course
+	CourseID    string
+	Instructors []string
+	IssuedAt    time.Time
+	VerifyURL   string
+}
+
+// Template lays out a certificate. Texts are Go templates over Data, with the
+// functions date (January 2, 2006), upper & join.
+type Template struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Frames []Frame `json:"frames"`
+	Lines  []Line  `json:"lines"`
+	Texts  []Text  `json:"texts"`
+
+	parsed []*template.Template
+}
+
+type Frame struct {
+	X         float64    `json:"x"`
+	Y         float64    `json:"y"`
+	Width     float64    `json:"width"`
+	Height    float64    `json:"height"`
+	LineWidth float64    `json:"lineWidth"`
+	Color     *pdf.Color `json:"color"`
+}
+
+type Line struct {
+	X1        float64    `json:"x1"`
+	Y1        float64    `json:"y1"`
+	X2        float64    `json:"x2"`
+	Y2        float64    `json:"y2"`
+	LineWidth float64    `json:"lineWidth"`
+	Color     *pdf.Color `json:"color"`
+}
+
+type Text struct {
+	Text  string     `json:"text"`
+	X     float64    `json:"x"`
+	Y     float64    `json:"y"`
+	Size  float64    `json:"size"`
+	Bold  bool       `json:"bold"`
+	Align string     `json:"align"`
+	Color *pdf.Color `json:"color"`
+}
+
+var funcs = template.FuncMap{
+	"date":  func(t time.Time) string { return t.Format("January 2, 2006") },
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+}
+
+var current *Template
+
+// UseTemplate sets the template certificates are rendered with. Until it is called, the built-in one is used.
+func UseTemplate(t *Template) {
+	current = t
+}
+
+// Render writes the certificate with the template in use.
+func Render(d Data) ([]byte, error) {
+	t := current
+	if t == nil {
+		var err error
+		if t, err = LoadTemplate(""); err != nil {
+			return nil, err
+		}
+	}
+	return t.Render(d)
+}
+
+// LoadTemplate reads a template from a JSON file. Without a file, the built-in template is returned.
+func LoadTemplate(path string) (*Template, error) {
+	data := defaultTemplate
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid certificate template: %w", err)
+	}
+	if err := t.parse(); err != nil {
+		return nil, fmt.Errorf("invalid certificate template: %w", err)
+	}
+	return &t, nil
+}
+
+// parse checks the template, and that every text renders against a sample certificate.
+func (t *Template) parse() error {
+	if t.Width <= 0 || t.Height <= 0 {
+		return fmt.Errorf("the page size must be positive")
+	}
+	sample := Data{Serial: "0000-0000-0000-0000", Name: "Name", Course: "Course", Instructors: []string{"Instructor"}, IssuedAt: time.Now()}
+	t.parsed = make([]*template.Template, len(t.Texts))
+	for i, text := range t.Texts {
+		if text.Size <= 0 {
+			return fmt.Errorf("text %d: the size must be positive", i+1)
+		}
+		switch text.Align {
+		case "", Left, Center, Right:
+		default:
+			return fmt.Errorf("text %d: unknown alignment %q", i+1, text.Align)
+		}
+		parsed, err := template.New(fmt.Sprintf("text %d", i+1)).Funcs(funcs).Option("missingkey=error").Parse(text.Text)
+		if err != nil {
+			return err
+		}
+		if err := parsed.Execute(&bytes.Buffer{}, sample); err != nil {
+			return err
+		}
+		t.parsed[i] = parsed
+	}
+	return nil
+}
+
+// Render writes the certificate as a PDF.
+func (t *Template) Render(d Data) ([]byte, error) {
+	doc := pdf.New(t.Width, t.Height)
+	doc.Info = pdf.Info{Title: "Certificate " + d.Serial, Author: "Praromvik", Subject: d.Course, Created: d.IssuedAt}
+	for _, f := range t.Frames {
+		doc.Rect(f.X, f.Y, f.Width, f.Height, f.LineWidth, color(f.Color))
+	}
+	for _, l := range t.Lines {
+		doc.Line(l.X1, l.Y1, l.X2, l.Y2, l.LineWidth, color(l.Color))
+	}
+	for i, text := range t.Texts {
+		var b strings.Builder
+		if err := t.parsed[i].Execute(&b, d); err != nil {
+			return nil, err
+		}
+		s := b.String()
+		if s == "" {
+			continue
+		}
+		font := pdf.Helvetica
+		if text.Bold {
+			font = pdf.HelveticaBold
+		}
+		size := text.Size
+		if width := pdf.Width(font, size, s); width > t.Width-2*margin {
+			size *= (t.Width - 2*margin) / width
+		}
+		x := text.X
+		switch text.Align {
+		case Center:
+			if x == 0 {
+				x = t.Width / 2
+			}
+			x -= pdf.Width(font, size, s) / 2
+		case Right:
+			x -= pdf.Width(font, size, s)
+		}
+		if err := doc.Text(x, text.Y, font, size, color(text.Color), s); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Bytes(), nil
+}
+
+func color(c *pdf.Color) pdf.Color {
+	if c == nil {
+		return pdf.Black
+	}
+	return *c
+}
@@ -0,0 +1,83 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clientip
+
+import (
+	"fmt"
+	"net"
+)
+
+// Binding decides how strictly a session is tied to the client which created it.
+type Binding string
+
+const (
+	// BindStrictIP requires the very same client address.
+	BindStrictIP Binding = "strict-ip"
+	// BindSubnet requires an address in the same /24 (IPv4) or /64 (IPv6) network.
+	BindSubnet Binding = "subnet"
+	// BindUserAgent only requires the same user agent.
+	BindUserAgent Binding = "user-agent"
+	// BindNone doesn't bind the session at all.
+	BindNone Binding = "none"
+)
+
+func ParseBinding(s string) (Binding, error) {
+	switch b := Binding(s); b {
+	case BindStrictIP, BindSubnet, BindUserAgent, BindNone:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown session binding '%s', expected one of %s, %s, %s or %s",
+		s, BindStrictIP, BindSubnet, BindUserAgent, BindNone)
+}
+
+// Matches reports whether a request from ip with userAgent may use a session
+// created from boundIP with boundUserAgent.
+func (b Binding) Matches(boundIP, boundUserAgent, ip, userAgent string) bool {
+	switch b {
+	case BindNone:
+		return true
+	case BindUserAgent:
+		return boundUserAgent == userAgent
+	case BindSubnet:
+		return boundUserAgent == userAgent && sameSubnet(ParseIP(boundIP), ParseIP(ip))
+	default:
+		return boundUserAgent == userAgent && boundIP == ip
+	}
+}
+
+func sameSubnet(a, b net.IP) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a4, b4 := a.To4(), b.To4(); a4 != nil || b4 != nil {
+		if a4 == nil || b4 == nil {
+			return false
+		}
+		mask := net.CIDRMask(24, 32)
+		return a4.Mask(mask).Equal(b4.Mask(mask))
+	}
+	mask := net.CIDRMask(64, 128)
+	return a.Mask(mask).Equal(b.Mask(mask))
+}
@@ -0,0 +1,125 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "2001:db8::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		remote   string
+		headers  map[string]string
+		expected string
+	}{
+		{name: "IPv4", remote: "203.0.113.7:5555", expected: "203.0.113.7"},
+		{name: "IPv6", remote: "[2001:db8::42]:5555", expected: "2001:db8::42"},
+		{
+			name:     "UntrustedRemoteIgnoresHeader",
+			remote:   "203.0.113.7:5555",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expected: "203.0.113.7",
+		},
+		{
+			name:     "TrustedProxy",
+			remote:   "10.0.0.2:5555",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expected: "198.51.100.1",
+		},
+		{
+			name:     "SpoofedChain",
+			remote:   "10.0.0.2:5555",
+			headers:  map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1, 10.0.0.3"},
+			expected: "198.51.100.1",
+		},
+		{
+			name:     "OnlyProxies",
+			remote:   "10.0.0.2:5555",
+			headers:  map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"},
+			expected: "10.0.0.4",
+		},
+		{
+			name:     "Forwarded",
+			remote:   "[2001:db8::1]:443",
+			headers:  map[string]string{"Forwarded": `for="[2001:db8:cafe::17]:4711";proto=https`, "X-Forwarded-For": "1.2.3.4"},
+			expected: "2001:db8:cafe::17",
+		},
+		{
+			name:     "MalformedHop",
+			remote:   "10.0.0.2:5555",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.1, unknown"},
+			expected: "10.0.0.2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = test.remote
+			for k, v := range test.headers {
+				r.Header.Set(k, v)
+			}
+			if got := resolver.ClientIP(r); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestBinding(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  Binding
+		ip       string
+		ua       string
+		expected bool
+	}{
+		{name: "StrictSame", binding: BindStrictIP, ip: "203.0.113.7", ua: "app", expected: true},
+		{name: "StrictMoved", binding: BindStrictIP, ip: "203.0.113.8", ua: "app", expected: false},
+		{name: "SubnetMoved", binding: BindSubnet, ip: "203.0.113.200", ua: "app", expected: true},
+		{name: "SubnetLeft", binding: BindSubnet, ip: "198.51.100.7", ua: "app", expected: false},
+		{name: "SubnetOtherAgent", binding: BindSubnet, ip: "203.0.113.7", ua: "curl", expected: false},
+		{name: "UserAgentMoved", binding: BindUserAgent, ip: "198.51.100.7", ua: "app", expected: true},
+		{name: "UserAgentOtherAgent", binding: BindUserAgent, ip: "203.0.113.7", ua: "curl", expected: false},
+		{name: "None", binding: BindNone, ip: "198.51.100.7", ua: "curl", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.binding.Matches("203.0.113.7", "app", test.ip, test.ua); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+	if !BindSubnet.Matches("2001:db8:1:2::1", "app", "2001:db8:1:2:ffff::9", "app") {
+		t.Fatalf("expected addresses in the same /64 to match")
+	}
+}
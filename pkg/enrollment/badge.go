@@ -0,0 +1,132 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package enrollment
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/pkg/ledger"
+	"github.com/praromvik/praromvik/pkg/openbadges"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BadgeIssuer is the profile badges are awarded by. Link builds the public address of a path.
+func BadgeIssuer(link func(path string) string) openbadges.Issuer {
+	return openbadges.Issuer{ID: link("/api/badges/issuer"), Name: "Praromvik", URL: link("/")}
+}
+
+// CourseAchievement describes completing the course. Once the course is deleted, it is described
+// from what its certificates hold of it, so that the badges awarded for it stay verifiable.
+func CourseAchievement(courseID string, link func(path string) string) (openbadges.Achievement, error) {
+	c, err := course.GetCourse(courseID)
+	if err == nil {
+		return achievement(c.CourseId, c.Title, c.Description, link), nil
+	}
+	if !errors.Is(err, course.ErrCourseNotFound) {
+		return openbadges.Achievement{}, err
+	}
+	issued, err := certificate.FindByCourse(courseID)
+	if errors.Is(err, certificate.ErrCertificateNotFound) {
+		return openbadges.Achievement{}, course.ErrCourseNotFound
+	}
+	if err != nil {
+		return openbadges.Achievement{}, err
+	}
+	return achievement(issued.Course, issued.CourseTitle, "", link), nil
+}
+
+func achievement(courseID string, title string, description string, link func(path string) string) openbadges.Achievement {
+	if description == "" {
+		description = fmt.Sprintf("Completed the course %s on Praromvik.", title)
+	}
+	return openbadges.Achievement{
+		ID:          link("/api/badges/courses/" + courseID),
+		Name:        title,
+		Description: description,
+		Criteria:    fmt.Sprintf("Complete every content of the course %s, and earn its certificate.", title),
+		Image:       link("/api/badges/courses/" + courseID + "/image.png"),
+	}
+}
+
+// CertificateBadge awards the badge of the course to whom the certificate was issued. Its ID is the
+// hosted 2.0 assertion; the serial salts the hashed email of the recipient, as stored at issuance.
+func CertificateBadge(serial string, link func(path string) string) (*openbadges.Award, error) {
+	c, err := certificate.Get(serial)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := recipientIdentity(c)
+	if err != nil {
+		return nil, err
+	}
+	a, err := CourseAchievement(c.Course, link)
+	if err != nil {
+		return nil, err
+	}
+	return &openbadges.Award{
+		ID:          link("/api/badges/assertions/" + c.Serial),
+		Identity:    identity,
+		Salt:        c.Serial,
+		IssuedOn:    c.IssuedAt,
+		Evidence:    link("/verify/" + c.Serial),
+		Achievement: a,
+		Issuer:      BadgeIssuer(link),
+	}, nil
+}
+
+// recipientIdentity returns the hashed email the certificate was issued to. A certificate issued before it
+// was stored gets it from the current email of the user, and keeps it from then on.
+func recipientIdentity(c *certificate.Certificate) (string, error) {
+	if c.Recipient != "" {
+		return c.Recipient, nil
+	}
+	recipient, err := user.GetByUUID(c.UserUUID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", fmt.Errorf("%w: its user is gone", certificate.ErrCertificateNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	identity := openbadges.IdentityHash(recipient.Email, c.Serial)
+	if err := c.SetRecipient(identity); err != nil {
+		return "", err
+	}
+	return identity, nil
+}
+
+// SignCredential signs the award as a 3.0 credential with the ledger key, its ID being the hosted credential.
+func SignCredential(award openbadges.Award, link func(path string) string) (string, error) {
+	if ledgerKey == nil {
+		return "", ErrNoLedgerKey
+	}
+	award.ID = link("/api/badges/credentials/" + award.Salt)
+	return openbadges.SignCredential(award.CredentialV3(), ledgerKey, ledger.KeyID(ledgerKey.Public().(ed25519.PublicKey)))
+}
@@ -0,0 +1,173 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package enrollment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/praromvik/praromvik/models/asset"
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/certificate"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	pcertificate "github.com/praromvik/praromvik/pkg/certificate"
+	"github.com/praromvik/praromvik/pkg/mailer"
+	"github.com/praromvik/praromvik/pkg/openbadges"
+	"github.com/praromvik/praromvik/pkg/pdf"
+)
+
+var ErrNotCompleted = errors.New("the course is not completed yet")
+
+// IssueCertificate certifies that the student completed the course, once every content of it is completed.
+// A course is certified once: issuing again returns the certificate issued before, finishing what failed
+// the first time, from rendering its PDF to recording it in the certificates of the student.
+// Link builds the public address of a path.
+func IssueCertificate(courseID string, userName string, link func(path string) string) (*certificate.Certificate, error) {
+	c, err := course.GetCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsStudent(userName) {
+		return nil, course.ErrNotEnrolled
+	}
+	u := &user.User{UserName: userName}
+	if err := u.GetFromMongo(); err != nil {
+		return nil, err
+	}
+	if issued, err := certificate.GetByCourse(u.UUID, courseID); err == nil && issued.Asset != "" {
+		return issued, deliver(u, issued, link)
+	} else if err != nil && !errors.Is(err, certificate.ErrCertificateNotFound) {
+		return nil, err
+	}
+
+	records, err := course.ListProgress(u.UUID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := course.Summarize(courseID, records)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Total == 0 || summary.Completed < summary.Total {
+		return nil, ErrNotCompleted
+	}
+
+	serial, err := pcertificate.NewSerial()
+	if err != nil {
+		return nil, err
+	}
+	issued, err := certificate.Claim(&certificate.Certificate{
+		Serial:      serial,
+		UserUUID:    u.UUID,
+		Name:        printedName(u),
+		Course:      c.CourseId,
+		CourseTitle: c.Title,
+		Instructors: c.Instructors,
+		Recipient:   openbadges.IdentityHash(u.Email, serial),
+		IssuedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if issued.Asset == "" {
+		rendered, err := render(issued, link)
+		if err != nil {
+			return nil, err
+		}
+		if !rendered {
+			// Rendered concurrently.
+			if issued, err = certificate.GetByCourse(u.UUID, courseID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return issued, deliver(u, issued, link)
+}
+
+// deliver records the rendered certificate in the certificates of the student, then enters it in the ledger,
+// audits & mails it. Only the first call records it & does the rest, so that a retry after a failure does it
+// once, as does a concurrent issuance.
+func deliver(u *user.User, issued *certificate.Certificate, link func(path string) string) error {
+	if slices.Contains(u.Certificates, issued.Serial) {
+		return nil
+	}
+	added, err := u.AddCertificate(issued.Serial)
+	if err != nil || !added {
+		return err
+	}
+	if err := appendToLedger(issued); err != nil {
+		// The next checkpoint enters it.
+		log.Printf("failed to append the certificate %s to the ledger: %v", issued.Serial, err)
+	}
+	audit.Log(&audit.Event{Type: audit.CertificateIssue, Subject: u.UserName, Details: map[string]string{"course": issued.Course, "serial": issued.Serial}})
+	body := fmt.Sprintf("Congratulations on completing %s!\n\nYour certificate: %s\n", issued.CourseTitle, link("/verify/"+issued.Serial))
+	if err := mailer.Send(mailer.Message{To: u.Email, Subject: "Your certificate of " + issued.CourseTitle, Body: fmt.Sprintf("Hi %s,\n\n%s", u.UserName, body)}); err != nil {
+		log.Printf("failed to mail the certificate %s to %s: %v", issued.Serial, u.UserName, err)
+	}
+	return nil
+}
+
+// render writes the PDF of the certificate & stores it as an asset of the student.
+// It reports false if a concurrent issuance attached its PDF first.
+func render(c *certificate.Certificate, link func(path string) string) (bool, error) {
+	data, err := pcertificate.Render(pcertificate.Data{
+		Serial:      c.Serial,
+		Name:        c.Name,
+		Course:      c.CourseTitle,
+		CourseID:    c.Course,
+		Instructors: c.Instructors,
+		IssuedAt:    c.IssuedAt,
+		VerifyURL:   link("/verify/" + c.Serial),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to render certificate: %w", err)
+	}
+	document := asset.NewPDF(asset.KindCertificate, c.UserUUID, data)
+	if err := document.AddAssetToDB(); err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256(data)
+	attached, err := c.SetDocument(document.ID, hex.EncodeToString(sum[:]))
+	if err != nil || !attached {
+		if err := asset.Delete(document.ID); err != nil {
+			log.Printf("failed to delete the unused certificate document %s: %v", document.ID, err)
+		}
+	}
+	return attached, err
+}
+
+// printedName is the display name of the user, unless the certificate fonts can't write it.
+func printedName(u *user.User) string {
+	if u.DisplayName != "" && pdf.Encodable(u.DisplayName) {
+		return u.DisplayName
+	}
+	return u.UserName
+}
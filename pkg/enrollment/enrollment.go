@@ -0,0 +1,120 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package enrollment keeps the students of courses & the courses of users in step.
+package enrollment
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/models/waitlist"
+)
+
+// Enroll adds the user to the students of the course, if it has a seat left & hasn't ended,
+// and the course to the enrolled courses of the user. A seat offered from the waitlist is claimed.
+func Enroll(courseID string, userName string, now time.Time) (*course.Course, error) {
+	c, err := course.GetCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Ended(now) {
+		return nil, course.ErrCourseEnded
+	}
+	entry, err := takeSeat(c, userName, now)
+	if err != nil {
+		return nil, err
+	}
+	u := &user.User{UserName: userName}
+	if err := u.AddEnrolledCourse(utils.Info{Name: c.Title, UUID: c.CourseId}); err != nil {
+		// Give the seat back, so that both records agree.
+		if err := course.RemoveStudent(courseID, userName); err != nil {
+			log.Printf("failed to give back the seat of %s in %s: %v", userName, courseID, err)
+		}
+		return nil, err
+	}
+	if entry != nil {
+		if err := entry.Remove(); err != nil {
+			log.Printf("failed to take %s off the waitlist of %s: %v", userName, courseID, err)
+		}
+	}
+	return c, nil
+}
+
+// takeSeat claims the seat offered to the user from the waitlist, and returns their entry.
+// Otherwise, it takes a free seat, unless others are already waiting for one.
+func takeSeat(c *course.Course, userName string, now time.Time) (*waitlist.Entry, error) {
+	entry, err := waitlist.Get(c.CourseId, userName)
+	if err != nil && !errors.Is(err, waitlist.ErrNotWaiting) {
+		return nil, err
+	}
+	if entry != nil && entry.Status == waitlist.StatusOffered {
+		if entry.OfferExpired(now) {
+			// Passed on now rather than at the next sweep.
+			if _, err := ExpireOffers(now); err != nil {
+				log.Printf("failed to expire the waitlist offers: %v", err)
+			}
+			return nil, waitlist.ErrOfferExpired
+		}
+		return entry, course.ClaimSeat(c.CourseId, userName)
+	}
+	if c.IsStudent(userName) {
+		return nil, course.ErrAlreadyEnrolled
+	}
+	waiting, err := waitlist.CountWaiting(c.CourseId)
+	if err != nil {
+		return nil, err
+	}
+	if waiting > 0 {
+		return nil, course.ErrCourseFull
+	}
+	return entry, course.AddStudent(c.CourseId, userName)
+}
+
+// Unenroll removes the user from the course. The students of a course which has ended stay as they are.
+func Unenroll(courseID string, userName string, now time.Time) (*course.Course, error) {
+	c, err := course.GetCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Ended(now) {
+		return nil, course.ErrCourseEnded
+	}
+	if err := course.RemoveStudent(courseID, userName); err != nil {
+		return nil, err
+	}
+	u := &user.User{UserName: userName}
+	if err := u.RemoveEnrolledCourse(courseID); err != nil {
+		// The seat is already free, a stale entry in the courses of the user grants nothing.
+		log.Printf("failed to remove %s from the courses of %s: %v", courseID, userName, err)
+	}
+	if err := Promote(courseID, now); err != nil {
+		log.Printf("failed to promote the waitlist of %s: %v", courseID, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,270 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/exam"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/assessment"
+)
+
+var (
+	ErrInvalidAnswer = errors.New("invalid answer")
+	ErrAttemptOver   = errors.New("the time of the attempt is over, its saved answers were submitted")
+)
+
+// saveRetries bounds the retries of a save racing with others of the same attempt.
+const saveRetries = 3
+
+// StartExam starts the next attempt of the student at the exam, or returns the one in progress.
+// An attempt whose time ran out is submitted first.
+func StartExam(e *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error) {
+	c, err := course.GetCourse(e.CourseRef)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsStudent(student.Name) {
+		return nil, course.ErrNotEnrolled
+	}
+	if current, err := exam.InProgress(student.UUID, e.ExamID); err == nil {
+		if assessment.Accepts(current.Deadline, now) {
+			return current, nil
+		}
+		if err := finalize(e, current, student.Name, current.Deadline, true); err != nil && !errors.Is(err, exam.ErrAttemptConflict) {
+			return nil, err
+		}
+	} else if !errors.Is(err, exam.ErrAttemptNotFound) {
+		return nil, err
+	}
+
+	attempts, err := exam.ListAttempts(student.UUID, e.ExamID)
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := e.Schedule().Start(now, len(attempts))
+	if err != nil {
+		return nil, err
+	}
+	a := &exam.Attempt{
+		UserUUID:  student.UUID,
+		Exam:      e.ExamID,
+		Course:    e.CourseRef,
+		Number:    len(attempts) + 1,
+		StartedAt: now.UTC(),
+		Deadline:  deadline.UTC(),
+	}
+	if err := a.Start(); errors.Is(err, exam.ErrAttemptConflict) {
+		// Started concurrently, that attempt is the one in progress.
+		return exam.InProgress(student.UUID, e.ExamID)
+	} else if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// CurrentAttempt returns the attempt of the student in progress, submitting it if its time ran out.
+func CurrentAttempt(e *exam.Exam, student utils.Info, now time.Time) (*exam.Attempt, error) {
+	a, err := exam.InProgress(student.UUID, e.ExamID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Accepts(a.Deadline, now) {
+		return a, nil
+	}
+	if err := finalize(e, a, student.Name, a.Deadline, true); err != nil && !errors.Is(err, exam.ErrAttemptConflict) {
+		return nil, err
+	}
+	return exam.GetAttempt(a.ID)
+}
+
+// SaveAnswers saves the answers into the attempt of the student in progress, over those saved before.
+func SaveAnswers(e *exam.Exam, student utils.Info, answers []assessment.Answer, now time.Time) (*exam.Attempt, error) {
+	for try := 1; ; try++ {
+		a, err := answer(e, student, answers, now)
+		if err != nil {
+			return nil, err
+		}
+		err = a.SaveAnswers(a.Answers, now)
+		if err == nil {
+			return a, nil
+		}
+		if !errors.Is(err, exam.ErrAttemptConflict) || try == saveRetries {
+			return nil, err
+		}
+	}
+}
+
+// SubmitExam submits the attempt of the student in progress with the last answers, and scores it.
+func SubmitExam(e *exam.Exam, student utils.Info, answers []assessment.Answer, now time.Time) (*exam.Attempt, error) {
+	for try := 1; ; try++ {
+		a, err := answer(e, student, answers, now)
+		if err != nil {
+			return nil, err
+		}
+		err = finalize(e, a, student.Name, now, false)
+		if err == nil {
+			return a, nil
+		}
+		if !errors.Is(err, exam.ErrAttemptConflict) || try == saveRetries {
+			return nil, err
+		}
+	}
+}
+
+// answer returns the attempt in progress with the answers applied, not saved yet.
+// An attempt whose time ran out is submitted as it was saved, and ErrAttemptOver returned.
+func answer(e *exam.Exam, student utils.Info, answers []assessment.Answer, now time.Time) (*exam.Attempt, error) {
+	a, err := exam.InProgress(student.UUID, e.ExamID)
+	if err != nil {
+		return nil, err
+	}
+	if !assessment.Accepts(a.Deadline, now) {
+		if err := finalize(e, a, student.Name, a.Deadline, true); err != nil && !errors.Is(err, exam.ErrAttemptConflict) {
+			return nil, err
+		}
+		return nil, ErrAttemptOver
+	}
+	a.Answers, err = assessment.Merge(e.Questions, a.Answers, answers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAnswer, err)
+	}
+	return a, nil
+}
+
+// finalize scores & submits the attempt, and records that the student took part in the exam.
+// It returns exam.ErrAttemptConflict if the attempt changed since it was read.
+func finalize(e *exam.Exam, a *exam.Attempt, userName string, at time.Time, auto bool) error {
+	score := assessment.Grade(e.Questions, a.Answers, e.PassPercent)
+	submitted, err := a.Submit(score, at, auto)
+	if err != nil {
+		return err
+	}
+	if !submitted {
+		return exam.ErrAttemptConflict
+	}
+	u := &user.User{UUID: a.UserUUID}
+	if err := u.AddParticipatedExam(utils.Info{Name: e.Title, UUID: e.ExamID}); err != nil {
+		log.Printf("failed to record the exam %s of %s: %v", e.ExamID, userName, err)
+	}
+	audit.Log(&audit.Event{Type: audit.ExamSubmit, Subject: userName, Details: map[string]string{
+		"course":  e.CourseRef,
+		"exam":    e.ExamID,
+		"attempt": strconv.Itoa(a.Number),
+		"percent": strconv.FormatFloat(score.Percent, 'f', -1, 64),
+		"auto":    strconv.FormatBool(auto),
+	}})
+	return nil
+}
+
+// ExpireAttempts submits the attempts whose time ran out, as they were saved, and returns how many.
+func ExpireAttempts(now time.Time) (int, error) {
+	attempts, err := exam.ListExpired(now)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for i := range attempts {
+		a := &attempts[i]
+		e, err := exam.Get(a.Course, a.Exam)
+		if errors.Is(err, exam.ErrExamNotFound) {
+			// Deleted meanwhile, with its attempts.
+			continue
+		}
+		// A failing attempt is left to the next sweep, without holding up the others.
+		if err != nil {
+			log.Printf("failed to get exam %s of attempt %s: %v", a.Exam, a.ID, err)
+			continue
+		}
+		u, err := user.GetByUUID(a.UserUUID)
+		if err != nil {
+			log.Printf("failed to get the student of attempt %s: %v", a.ID, err)
+			continue
+		}
+		if err := finalize(e, a, u.UserName, a.Deadline, true); errors.Is(err, exam.ErrAttemptConflict) {
+			// Saved or submitted meanwhile, the next sweep looks at it again if need be.
+			continue
+		} else if err != nil {
+			log.Printf("failed to submit expired attempt %s: %v", a.ID, err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// SweepExams submits the attempts whose time ran out every interval until the context is done.
+func SweepExams(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := ExpireAttempts(time.Now())
+			if err != nil {
+				log.Printf("failed to submit expired exam attempts: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("submitted %d expired exam attempts", expired)
+			}
+		}
+	}
+}
+
+// DeleteExam removes the exam with its attempts, and from the exams of its participants.
+func DeleteExam(e *exam.Exam) error {
+	if err := exam.Delete(e); err != nil {
+		return err
+	}
+	return user.ForgetExam(e.ExamID)
+}
+
+// DeleteCourseExams removes the exams of a deleted course, like DeleteExam.
+func DeleteCourseExams(courseID string) error {
+	exams, err := exam.List(courseID)
+	if err != nil {
+		return err
+	}
+	if err := exam.DeleteCourse(courseID); err != nil {
+		return err
+	}
+	for _, e := range exams {
+		if err := user.ForgetExam(e.ExamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package enrollment
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/praromvik/praromvik/models/certificate"
+	mledger "github.com/praromvik/praromvik/models/ledger"
+	pcertificate "github.com/praromvik/praromvik/pkg/certificate"
+	"github.com/praromvik/praromvik/pkg/ledger"
+)
+
+var (
+	ErrNotCheckpointed = errors.New("the certificate isn't in a checkpoint of the ledger yet")
+	ErrNoLedgerKey     = errors.New("no ledger key is configured")
+)
+
+// appendAttempts bounds the retries of an append racing with others.
+const appendAttempts = 5
+
+var ledgerKey ed25519.PrivateKey
+
+// UseLedgerKey sets the key checkpoints are signed with. Until it is called, no checkpoint is made.
+func UseLedgerKey(key ed25519.PrivateKey) {
+	ledgerKey = key
+}
+
+// LedgerPublicKey returns the key checkpoints are verified with.
+func LedgerPublicKey() (ed25519.PublicKey, error) {
+	if ledgerKey == nil {
+		return nil, ErrNoLedgerKey
+	}
+	return ledgerKey.Public().(ed25519.PublicKey), nil
+}
+
+// ledgerRecord is what the ledger holds of the certificate.
+func ledgerRecord(c *certificate.Certificate) pcertificate.Record {
+	return pcertificate.Record{
+		Serial:      c.Serial,
+		Name:        c.Name,
+		Course:      c.Course,
+		CourseTitle: c.CourseTitle,
+		Instructors: c.Instructors,
+		IssuedAt:    c.IssuedAt.UTC().Format(time.RFC3339),
+		SHA256:      c.SHA256,
+	}
+}
+
+// appendToLedger enters the certificate at the end of the ledger, once.
+func appendToLedger(c *certificate.Certificate) error {
+	if c.LedgerIndex != nil {
+		return nil
+	}
+	entry, err := mledger.GetBySerial(c.Serial)
+	if errors.Is(err, mledger.ErrEntryNotFound) {
+		entry, err = appendLeaf(c.Serial, ledgerRecord(c).Leaf())
+	}
+	if err != nil {
+		return err
+	}
+	return c.SetLedgerIndex(entry.Seq)
+}
+
+// appendLeaf chains the leaf to the last entry. Concurrent appends take the sequence numbers in turn.
+func appendLeaf(serial string, leaf ledger.Hash) (*mledger.Entry, error) {
+	for attempt := 1; ; attempt++ {
+		var prev ledger.Hash
+		var seq int64
+		last, err := mledger.Last()
+		switch {
+		case errors.Is(err, mledger.ErrEmpty):
+		case err != nil:
+			return nil, err
+		default:
+			if prev, err = ledger.ParseHash(last.Hash); err != nil {
+				return nil, err
+			}
+			seq = last.Seq + 1
+		}
+		link := ledger.Chain(prev, leaf)
+		entry := &mledger.Entry{
+			Seq:        seq,
+			Serial:     serial,
+			Leaf:       link.Leaf.String(),
+			Prev:       link.Prev.String(),
+			Hash:       link.Hash.String(),
+			AppendedAt: time.Now().UTC(),
+		}
+		err = mledger.Append(entry)
+		if err == nil {
+			return entry, nil
+		}
+		if !errors.Is(err, mledger.ErrConflict) || attempt == appendAttempts {
+			return nil, err
+		}
+	}
+}
+
+// CheckpointLedger enters the certificates missing from the ledger, checks that the chain is intact,
+// and signs the head of the ledger if it grew since the last checkpoint.
+func CheckpointLedger(now time.Time) (*mledger.Checkpoint, error) {
+	if ledgerKey == nil {
+		return nil, ErrNoLedgerKey
+	}
+	pending, err := certificate.ListUnledgered()
+	if err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		if err := appendToLedger(&pending[i]); err != nil {
+			return nil, fmt.Errorf("failed to append the certificate %s: %w", pending[i].Serial, err)
+		}
+	}
+
+	entries, err := mledger.List(math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := mledger.LatestCheckpoint()
+	if err != nil && !errors.Is(err, mledger.ErrNoCheckpoint) {
+		return nil, err
+	}
+	if len(entries) == 0 || (latest != nil && latest.Size == int64(len(entries))) {
+		return latest, nil
+	}
+	links, err := mledger.Links(entries)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if e.Seq != int64(i) {
+			return nil, fmt.Errorf("the ledger was tampered with: entry %d is missing", i)
+		}
+	}
+	if err := ledger.VerifyChain(links); err != nil {
+		return nil, fmt.Errorf("the ledger was tampered with: %w", err)
+	}
+	if latest != nil && (latest.Size > int64(len(links)) || links[latest.Size-1].Hash.String() != latest.Head) {
+		return nil, fmt.Errorf("the ledger was tampered with: it doesn't extend the checkpoint of %d entries", latest.Size)
+	}
+
+	leaves := make([]ledger.Hash, len(links))
+	for i, link := range links {
+		leaves[i] = link.Leaf
+	}
+	signed := ledger.Checkpoint{
+		Size:      int64(len(leaves)),
+		Root:      ledger.Root(leaves),
+		Head:      links[len(links)-1].Hash,
+		CreatedAt: now.UTC().Truncate(time.Second),
+	}
+	signed.Sign(ledgerKey)
+	c := &mledger.Checkpoint{
+		Size:      signed.Size,
+		Root:      signed.Root.String(),
+		Head:      signed.Head.String(),
+		CreatedAt: signed.CreatedAt,
+		KeyID:     signed.KeyID,
+		Signature: signed.Signature,
+	}
+	if err := mledger.AddCheckpoint(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CertificateProof proves that the certificate is in the ledger, as of its latest checkpoint.
+func CertificateProof(serial string) (*pcertificate.Proof, error) {
+	c, err := certificate.Get(serial)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := mledger.GetBySerial(serial)
+	if errors.Is(err, mledger.ErrEntryNotFound) {
+		return nil, ErrNotCheckpointed
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := ledgerRecord(c)
+	if record.Leaf().String() != entry.Leaf {
+		return nil, fmt.Errorf("the certificate %s doesn't match its ledger entry", serial)
+	}
+	latest, err := mledger.LatestCheckpoint()
+	if errors.Is(err, mledger.ErrNoCheckpoint) {
+		return nil, ErrNotCheckpointed
+	}
+	if err != nil {
+		return nil, err
+	}
+	if entry.Seq >= latest.Size {
+		return nil, ErrNotCheckpointed
+	}
+	checkpoint, err := latest.Parse()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := mledger.List(latest.Size)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]ledger.Hash, len(entries))
+	for i, e := range entries {
+		if leaves[i], err = ledger.ParseHash(e.Leaf); err != nil {
+			return nil, err
+		}
+	}
+	path, err := ledger.InclusionProof(leaves, entry.Seq)
+	if err != nil {
+		return nil, err
+	}
+	return &pcertificate.Proof{
+		Certificate: record,
+		Ledger:      ledger.Proof{LeafIndex: entry.Seq, AuditPath: path, Checkpoint: checkpoint},
+	}, nil
+}
+
+// SweepLedger checkpoints the ledger every interval until the context is done.
+func SweepLedger(ctx context.Context, interval time.Duration) {
+	if ledgerKey == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := CheckpointLedger(time.Now()); err != nil {
+				log.Printf("failed to checkpoint the certificate ledger: %v", err)
+			}
+		}
+	}
+}
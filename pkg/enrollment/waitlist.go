@@ -0,0 +1,221 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/user"
+	"github.com/praromvik/praromvik/models/waitlist"
+	"github.com/praromvik/praromvik/pkg/mailer"
+)
+
+var ErrSeatsLeft = errors.New("the course has seats left, enroll instead")
+
+// claimWindow is how long a user promoted from the waitlist has to claim their seat.
+var claimWindow = 48 * time.Hour
+
+// UseClaimWindow sets how long a user promoted from the waitlist has to claim their seat.
+func UseClaimWindow(d time.Duration) {
+	claimWindow = d
+}
+
+// JoinWaitlist queues the user for a seat of the full course, and returns their place.
+func JoinWaitlist(courseID string, userName string, now time.Time) (*waitlist.Entry, int64, error) {
+	c, err := course.GetCourse(courseID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.Ended(now) {
+		return nil, 0, course.ErrCourseEnded
+	}
+	if c.IsStudent(userName) {
+		return nil, 0, course.ErrAlreadyEnrolled
+	}
+	if c.SeatsLeft() != 0 {
+		waiting, err := waitlist.CountWaiting(courseID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if waiting == 0 {
+			return nil, 0, ErrSeatsLeft
+		}
+	}
+	e, err := waitlist.Join(courseID, userName)
+	if err != nil {
+		return nil, 0, err
+	}
+	position, err := e.Position()
+	return e, position, err
+}
+
+// WaitlistStatus returns the entry of the user in the waitlist of the course, and their place.
+func WaitlistStatus(courseID string, userName string) (*waitlist.Entry, int64, error) {
+	e, err := waitlist.Get(courseID, userName)
+	if err != nil {
+		return nil, 0, err
+	}
+	position, err := e.Position()
+	return e, position, err
+}
+
+// LeaveWaitlist takes the user off the waitlist. A seat offered to them goes to the next in line.
+func LeaveWaitlist(courseID string, userName string) error {
+	e, err := waitlist.Get(courseID, userName)
+	if err != nil {
+		return err
+	}
+	if err := e.Remove(); err != nil {
+		return err
+	}
+	if e.Status == waitlist.StatusOffered {
+		if err := course.ReleaseSeat(courseID, userName); err != nil {
+			return err
+		}
+		return Promote(courseID, time.Now())
+	}
+	return nil
+}
+
+// Promote offers the free seats of the course to the users waiting for it, in order.
+// Each offered seat is reserved until claimed, or until the claim window is over.
+func Promote(courseID string, now time.Time) error {
+	for {
+		c, err := course.GetCourse(courseID)
+		if err != nil {
+			return err
+		}
+		if c.Ended(now) || c.SeatsLeft() == 0 {
+			return nil
+		}
+		e, err := waitlist.OfferNext(courseID, now.Add(claimWindow))
+		if err != nil || e == nil {
+			return err
+		}
+		err = course.ReserveSeat(courseID, e.UserName)
+		if errors.Is(err, course.ErrAlreadyEnrolled) {
+			// Enrolled meanwhile, e.g. by the staff of the course.
+			if err := e.Remove(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			// Somebody took the seat meanwhile, the user keeps their place.
+			if requeueErr := e.Requeue(); requeueErr != nil {
+				log.Printf("failed to requeue %s on the waitlist of %s: %v", e.UserName, courseID, requeueErr)
+			}
+			if errors.Is(err, course.ErrCourseFull) {
+				return nil
+			}
+			return err
+		}
+		notify(e.UserName, fmt.Sprintf("A seat of %s is yours", c.Title),
+			fmt.Sprintf("A seat of the course %s freed up, and it is reserved for you until %s.\n"+
+				"Enroll in the course before then to claim it, or it goes to the next in line.\n",
+				c.Title, e.OfferExpiresAt.Format(time.RFC1123)))
+	}
+}
+
+// ExpireOffers withdraws the offers which weren't claimed in time, and passes their seats on.
+func ExpireOffers(now time.Time) (int, error) {
+	entries, err := waitlist.ExpiredOffers(now)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for i := range entries {
+		e := &entries[i]
+		if err := e.Remove(); err != nil {
+			return expired, err
+		}
+		if err := course.ReleaseSeat(e.Course, e.UserName); err != nil {
+			return expired, err
+		}
+		expired++
+		notify(e.UserName, "Your reserved seat has expired",
+			fmt.Sprintf("The seat of the course %s reserved for you wasn't claimed in time, and was passed on.\n"+
+				"Join the waitlist again if you are still interested.\n", e.Course))
+		if err := Promote(e.Course, now); err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// PromoteAll offers the free seats of every course somebody waits for, e.g. after its capacity grew.
+func PromoteAll(now time.Time) error {
+	courses, err := waitlist.CoursesWithWaiting()
+	if err != nil {
+		return err
+	}
+	for _, id := range courses {
+		if err := Promote(id, now); err != nil {
+			return fmt.Errorf("failed to promote the waitlist of '%s': %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Sweep expires the unclaimed offers & promotes the waitlists every interval until the context is done.
+func Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			expired, err := ExpireOffers(now)
+			if err != nil {
+				log.Printf("failed to expire waitlist offers: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("expired %d waitlist offers", expired)
+			}
+			if err := PromoteAll(now); err != nil {
+				log.Printf("failed to promote waitlists: %v", err)
+			}
+		}
+	}
+}
+
+// notify mails the user. A notification failing doesn't undo what it is about.
+func notify(userName string, subject string, body string) {
+	u := &user.User{UserName: userName}
+	if err := u.GetFromMongo(); err != nil {
+		log.Printf("failed to get %s to notify: %v", userName, err)
+		return
+	}
+	if err := mailer.Send(mailer.Message{To: u.Email, Subject: subject, Body: fmt.Sprintf("Hi %s,\n\n%s", userName, body)}); err != nil {
+		log.Printf("failed to notify %s: %v", userName, err)
+	}
+}
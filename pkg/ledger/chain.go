@@ -0,0 +1,61 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ledger
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Link is an entry as chained in the log: its hash covers the hash of the entry before it,
+// so that no entry can be changed, dropped or reordered without breaking every hash after it.
+type Link struct {
+	Leaf Hash
+	Prev Hash
+	Hash Hash
+}
+
+// Chain links the leaf to the hash of the entry before it, zero for the first entry.
+func Chain(prev Hash, leaf Hash) Link {
+	b := make([]byte, 0, 2*len(prev))
+	b = append(b, prev[:]...)
+	b = append(b, leaf[:]...)
+	return Link{Leaf: leaf, Prev: prev, Hash: sha256.Sum256(b)}
+}
+
+// VerifyChain checks that the links form an unbroken chain from the first entry of the log.
+func VerifyChain(links []Link) error {
+	var prev Hash
+	for i, link := range links {
+		if link.Prev != prev {
+			return fmt.Errorf("entry %d doesn't follow entry %d", i, i-1)
+		}
+		if Chain(prev, link.Leaf).Hash != link.Hash {
+			return fmt.Errorf("entry %d was altered", i)
+		}
+		prev = link.Hash
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ledger
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Checkpoint is the signed head of the log once it held Size entries.
+type Checkpoint struct {
+	Size      int64     `json:"size"`
+	Root      Hash      `json:"root"` // Merkle tree head of the entries
+	Head      Hash      `json:"head"` // hash of the last entry of the chain
+	CreatedAt time.Time `json:"createdAt"`
+	KeyID     string    `json:"keyId,omitempty"`
+	Signature []byte    `json:"signature,omitempty"`
+}
+
+// Proof shows that the entry at LeafIndex is in the log of the checkpoint.
+type Proof struct {
+	LeafIndex  int64      `json:"leafIndex"`
+	AuditPath  []Hash     `json:"auditPath"`
+	Checkpoint Checkpoint `json:"checkpoint"`
+}
+
+// Verify checks that the leaf is in the log of the checkpoint, and that the checkpoint is signed by the key.
+func (p Proof) Verify(leaf Hash, key ed25519.PublicKey) error {
+	if err := p.Checkpoint.Verify(key); err != nil {
+		return err
+	}
+	return VerifyInclusion(leaf, p.LeafIndex, p.Checkpoint.Size, p.AuditPath, p.Checkpoint.Root)
+}
+
+// message is what the signature covers.
+func (c Checkpoint) message() []byte {
+	return []byte(fmt.Sprintf("praromvik certificate ledger\n%d\n%s\n%s\n%s\n",
+		c.Size, c.Root, c.Head, c.CreatedAt.UTC().Format(time.RFC3339)))
+}
+
+func (c *Checkpoint) Sign(key ed25519.PrivateKey) {
+	c.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	c.Signature = ed25519.Sign(key, c.message())
+}
+
+func (c Checkpoint) Verify(key ed25519.PublicKey) error {
+	if len(c.Signature) == 0 {
+		return errors.New("the checkpoint isn't signed")
+	}
+	if id := KeyID(key); c.KeyID != id {
+		return fmt.Errorf("the checkpoint is signed with the key %s, not %s", c.KeyID, id)
+	}
+	if !ed25519.Verify(key, c.message(), c.Signature) {
+		return errors.New("invalid checkpoint signature")
+	}
+	return nil
+}
+
+// KeyID names a public key by the start of its digest.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadPrivateKey reads an Ed25519 key from a PKCS #8 PEM file, as written by `openssl genpkey -algorithm ed25519`.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ledger key: %w", err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid ledger key: expected an Ed25519 key")
+	}
+	return private, nil
+}
+
+// LoadPublicKey reads an Ed25519 public key from a PEM file.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid public key: expected an Ed25519 key")
+	}
+	return public, nil
+}
+
+// MarshalPublicKey writes the public key as PEM.
+func MarshalPublicKey(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
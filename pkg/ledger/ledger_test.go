@@ -0,0 +1,227 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ledger
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The test vectors of RFC 6962 implementations
+var vectorLeaves = []string{
+	"", "\x00", "\x10", "\x20\x21", "\x30\x31", "\x40\x41\x42\x43",
+	"\x50\x51\x52\x53\x54\x55\x56\x57", "\x60\x61\x62\x63\x64\x65\x66\x67\x68\x69\x6a\x6b\x6c\x6d\x6e\x6f",
+}
+
+var vectorRoots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func leaves(n int) []Hash {
+	hashes := make([]Hash, n)
+	for i := range hashes {
+		hashes[i] = LeafHash([]byte(fmt.Sprintf("certificate %d", i)))
+	}
+	return hashes
+}
+
+func TestRoot(t *testing.T) {
+	if expected, got := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Root(nil).String(); got != expected {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	hashes := make([]Hash, len(vectorLeaves))
+	for i, leaf := range vectorLeaves {
+		hashes[i] = LeafHash([]byte(leaf))
+	}
+	for i, expected := range vectorRoots {
+		t.Run(fmt.Sprintf("Size%d", i+1), func(t *testing.T) {
+			if got := Root(hashes[:i+1]).String(); got != expected {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestInclusion(t *testing.T) {
+	for size := 1; size <= 33; size++ {
+		tree := leaves(size)
+		root := Root(tree)
+		for index := 0; index < size; index++ {
+			proof, err := InclusionProof(tree, int64(index))
+			if err != nil {
+				t.Fatalf("expected %v, got %v", nil, err)
+			}
+			if err := VerifyInclusion(tree[index], int64(index), int64(size), proof, root); err != nil {
+				t.Fatalf("size %d, leaf %d: expected %v, got %v", size, index, nil, err)
+			}
+		}
+	}
+
+	tree := leaves(11)
+	root := Root(tree)
+	proof, _ := InclusionProof(tree, 6)
+	altered := append([]Hash{}, proof...)
+	altered[1][0] ^= 1
+	tests := []struct {
+		name  string
+		leaf  Hash
+		index int64
+		size  int64
+		proof []Hash
+	}{
+		{name: "OtherLeaf", leaf: tree[5], index: 6, size: 11, proof: proof},
+		{name: "OtherIndex", leaf: tree[6], index: 5, size: 11, proof: proof},
+		{name: "OtherSize", leaf: tree[6], index: 6, size: 8, proof: proof},
+		{name: "IndexOutOfTree", leaf: tree[6], index: 11, size: 11, proof: proof},
+		{name: "AlteredPath", leaf: tree[6], index: 6, size: 11, proof: altered},
+		{name: "TooShort", leaf: tree[6], index: 6, size: 11, proof: proof[:len(proof)-1]},
+		{name: "TooLong", leaf: tree[6], index: 6, size: 11, proof: append(proof, root)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := VerifyInclusion(test.leaf, test.index, test.size, test.proof, root); err == nil {
+				t.Fatalf("expected an error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	build := func() []Link {
+		var links []Link
+		var prev Hash
+		for _, leaf := range leaves(5) {
+			link := Chain(prev, leaf)
+			links = append(links, link)
+			prev = link.Hash
+		}
+		return links
+	}
+	tests := []struct {
+		name   string
+		change func(links []Link) []Link
+		valid  bool
+	}{
+		{name: "Intact", change: func(links []Link) []Link { return links }, valid: true},
+		{name: "AlteredLeaf", change: func(links []Link) []Link { links[2].Leaf[0] ^= 1; return links }, valid: false},
+		{name: "Dropped", change: func(links []Link) []Link { return append(links[:2], links[3:]...) }, valid: false},
+		{name: "Reordered", change: func(links []Link) []Link { links[1], links[2] = links[2], links[1]; return links }, valid: false},
+		{name: "Truncated", change: func(links []Link) []Link { return links[:3] }, valid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyChain(test.change(build()))
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	tree := leaves(6)
+	c := Checkpoint{Size: 6, Root: Root(tree), Head: tree[5], CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	c.Sign(private)
+	path, _ := InclusionProof(tree, 3)
+	proof := Proof{LeafIndex: 3, AuditPath: path, Checkpoint: c}
+
+	resized := proof
+	resized.Checkpoint.Size = 7
+	tests := []struct {
+		name  string
+		proof Proof
+		leaf  Hash
+		key   ed25519.PublicKey
+		valid bool
+	}{
+		{name: "Valid", proof: proof, leaf: tree[3], key: public, valid: true},
+		{name: "OtherKey", proof: proof, leaf: tree[3], key: other, valid: false},
+		{name: "AlteredCheckpoint", proof: resized, leaf: tree[3], key: public, valid: false},
+		{name: "OtherLeaf", proof: proof, leaf: tree[4], key: public, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.proof.Verify(test.leaf, test.key)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "ledger.pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	loaded, err := LoadPrivateKey(privatePath)
+	if err != nil || !loaded.Equal(private) {
+		t.Fatalf("expected the private key, got %v", err)
+	}
+
+	data, err := MarshalPublicKey(public)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	publicPath := filepath.Join(dir, "ledger.pub")
+	if err := os.WriteFile(publicPath, data, 0o600); err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	if loaded, err := LoadPublicKey(publicPath); err != nil || !loaded.Equal(public) {
+		t.Fatalf("expected the public key, got %v", err)
+	}
+	if _, err := LoadPublicKey(privatePath); err == nil {
+		t.Fatalf("expected an error reading a private key as a public one")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package ledger builds the tamper-evident log of issued certificates: entries are hash-chained as they are appended,
+// and checkpoints sign the Merkle tree head of the log, so that anyone holding a checkpoint can check that an entry is
+// in the log with a short inclusion proof. Trees & proofs follow RFC 6962.
+package ledger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Hash is a SHA-256 digest, written in hex.
+type Hash [sha256.Size]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	parsed, err := ParseHash(string(text))
+	if err != nil {
+		return err
+	}
+	*h = parsed
+	return nil
+}
+
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("invalid hash %q", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// LeafHash hashes the data of an entry. The prefixes keep leaves & nodes apart.
+func LeafHash(data []byte) Hash {
+	return sha256.Sum256(append([]byte{0x00}, data...))
+}
+
+func nodeHash(left Hash, right Hash) Hash {
+	b := make([]byte, 0, 1+2*len(left))
+	b = append(b, 0x01)
+	b = append(b, left[:]...)
+	b = append(b, right[:]...)
+	return sha256.Sum256(b)
+}
+
+// Root returns the Merkle tree head of the leaves.
+func Root(leaves []Hash) Hash {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// InclusionProof returns the audit path proving that leaves[index] is in the tree of the leaves.
+func InclusionProof(leaves []Hash, index int64) ([]Hash, error) {
+	if index < 0 || index >= int64(len(leaves)) {
+		return nil, fmt.Errorf("leaf %d is out of a tree of %d", index, len(leaves))
+	}
+	return path(leaves, int(index)), nil
+}
+
+func path(leaves []Hash, index int) []Hash {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(path(leaves[:k], index), Root(leaves[k:]))
+	}
+	return append(path(leaves[k:], index-k), Root(leaves[:k]))
+}
+
+// VerifyInclusion checks that the leaf is at index in the tree of size leaves with the given root.
+func VerifyInclusion(leaf Hash, index int64, size int64, proof []Hash, root Hash) error {
+	if index < 0 || index >= size {
+		return fmt.Errorf("leaf %d is out of a tree of %d", index, size)
+	}
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return fmt.Errorf("the proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("the proof is too short")
+	}
+	if !bytes.Equal(r[:], root[:]) {
+		return fmt.Errorf("the proof leads to %s, not to the root %s", r, root)
+	}
+	return nil
+}
+
+// split is the largest power of two smaller than n.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
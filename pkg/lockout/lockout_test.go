@@ -0,0 +1,60 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockout(t *testing.T) {
+	policy := Policy{
+		FreeAttempts: 3,
+		BaseLockout:  30 * time.Second,
+		MaxLockout:   5 * time.Minute,
+		Window:       time.Hour,
+	}
+	tests := []struct {
+		name     string
+		failures int64
+		expected time.Duration
+	}{
+		{name: "NoFailure", failures: 0, expected: 0},
+		{name: "WithinFreeAttempts", failures: 3, expected: 0},
+		{name: "FirstLockout", failures: 4, expected: 30 * time.Second},
+		{name: "Doubled", failures: 5, expected: time.Minute},
+		{name: "DoubledTwice", failures: 6, expected: 2 * time.Minute},
+		{name: "Capped", failures: 8, expected: 5 * time.Minute},
+		{name: "CappedWithManyFailures", failures: 1000, expected: 5 * time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := policy.Lockout(test.failures); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
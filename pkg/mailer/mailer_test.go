@@ -0,0 +1,108 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mailer
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestSMTPMailerEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		expected string
+	}{
+		{name: "BareAddress", from: "no-reply@praromvik.com", expected: "MAIL FROM:<no-reply@praromvik.com>"},
+		{name: "DisplayName", from: "Praromvik <no-reply@praromvik.com>", expected: "MAIL FROM:<no-reply@praromvik.com>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addr, commands := fakeSMTPServer(t)
+			err := SMTPMailer{Addr: addr, From: test.from}.Send(Message{To: "student@praromvik.com", Subject: "Welcome", Body: "Hi"})
+			if err != nil {
+				t.Fatalf("expected the mail to be sent, got %v", err)
+			}
+			received := <-commands
+			if !strings.HasPrefix(received, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, received)
+			}
+		})
+	}
+}
+
+func TestSMTPMailerInvalidSender(t *testing.T) {
+	err := SMTPMailer{Addr: "127.0.0.1:1", From: "Praromvik"}.Send(Message{To: "student@praromvik.com", Subject: "Welcome"})
+	if err == nil {
+		t.Fatalf("expected an invalid sender to be refused")
+	}
+}
+
+// fakeSMTPServer accepts a single mail, and sends its MAIL command on the channel.
+func fakeSMTPServer(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	commands := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 localhost ready")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch strings.ToUpper(strings.SplitN(line, " ", 2)[0]) {
+			case "EHLO", "HELO":
+				text.PrintfLine("250 localhost")
+			case "MAIL":
+				commands <- line
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				if _, err := text.ReadDotLines(); err != nil {
+					return
+				}
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				return
+			default:
+				text.PrintfLine("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), commands
+}
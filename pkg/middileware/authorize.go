@@ -0,0 +1,112 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/praromvik/praromvik/models/course"
+	"github.com/praromvik/praromvik/models/utils"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+	"github.com/praromvik/praromvik/pkg/policy"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Authorizer guards routes with the authorization policy.
+type Authorizer struct {
+	Engine *policy.Engine
+}
+
+// Allow lets the request through only if the policy allows the signed-in user
+// to perform the action on the resource the route refers to.
+func (a Authorizer) Allow(kind string, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, err := sessionSubject(r)
+			if err != nil {
+				perror.HandleError(w, http.StatusUnauthorized, "Failed to retrieve user from session", err)
+				return
+			}
+			id := chi.URLParam(r, "id")
+			if id == "" {
+				id = chi.URLParam(r, "userName")
+			}
+			resource, err := LoadResource(kind, id, chi.URLParam(r, "courseRef"))
+			if err != nil {
+				perror.HandleError(w, http.StatusNotFound, "Error on getting resource", err)
+				return
+			}
+			if !a.Engine.Decide(subject, action, resource).Allowed {
+				perror.HandleError(w, http.StatusForbidden, "", fmt.Errorf("not allowed to %s %s", action, kind))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LoadResource gathers the attributes policy conditions need about a resource.
+// Everything within a course carries the staff & students of that course.
+func LoadResource(kind string, id string, courseRef string) (policy.Resource, error) {
+	resource := policy.Resource{Kind: kind, ID: id}
+	switch kind {
+	case utils.ResourceCourse:
+		courseRef = id
+	case utils.ResourceUser:
+		resource.Owner = id
+	}
+	if courseRef == "" {
+		return resource, nil
+	}
+	c, err := course.GetCourse(courseRef)
+	if err != nil {
+		return resource, err
+	}
+	resource.Instructors, resource.Moderators, resource.Students = c.Instructors, c.Moderators, c.Students
+	return resource, nil
+}
+
+func sessionSubject(r *http.Request) (policy.Subject, error) {
+	roles, err := auth.GetSessionRoles(r)
+	if err != nil {
+		return policy.Subject{}, err
+	}
+	subject := policy.Subject{}
+	for _, role := range roles {
+		subject.Roles = append(subject.Roles, string(role))
+	}
+	if len(roles) == 0 || (len(roles) == 1 && roles[0] == utils.None) {
+		return subject, nil
+	}
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		return subject, err
+	}
+	subject.Name = info.Name
+	return subject, nil
+}
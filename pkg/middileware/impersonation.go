@@ -0,0 +1,116 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/praromvik/praromvik/models/audit"
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+)
+
+type impersonationExemptKey struct{}
+
+var impersonatedWrites bool
+
+// AllowImpersonatedWrites lets impersonation sessions make state-changing requests.
+// They are blocked by default.
+func AllowImpersonatedWrites(allow bool) {
+	impersonatedWrites = allow
+}
+
+// AllowDuringImpersonation exempts a route from the impersonation write block,
+// e.g. the one stopping the impersonation. It has to run before SecurityMiddleware.
+func AllowDuringImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), impersonationExemptKey{}, true)))
+	})
+}
+
+// guardImpersonation marks & audits requests of impersonation sessions, and blocks the ones not allowed.
+// It reports whether the request may go on.
+func guardImpersonation(w http.ResponseWriter, r *http.Request) bool {
+	imp, err := auth.GetImpersonation(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusUnauthorized, "Failed to check impersonation", err)
+		return false
+	}
+	if imp == nil {
+		return true
+	}
+	if imp.Expired(time.Now()) {
+		if _, err := auth.StopImpersonation(w, r); err != nil {
+			log.Printf("failed to stop expired impersonation: %v", err)
+		}
+		audit.Log(&audit.Event{
+			Type:      audit.ImpersonationStop,
+			Actor:     imp.Admin,
+			Subject:   imp.Target,
+			IP:        auth.ClientIP(r),
+			UserAgent: r.UserAgent(),
+			Details:   map[string]string{"reason": "expired"},
+		})
+		perror.HandleError(w, http.StatusUnauthorized, "The impersonation has expired, repeat the request as yourself", nil)
+		return false
+	}
+
+	w.Header().Set(auth.ImpersonationHeader, imp.Target)
+	blocked := blocksImpersonation(r)
+	details := map[string]string{"method": r.Method, "path": r.URL.Path}
+	if blocked {
+		details["blocked"] = "true"
+	}
+	audit.Log(&audit.Event{
+		Type:      audit.ImpersonationRequest,
+		Actor:     imp.Admin,
+		Subject:   imp.Target,
+		IP:        auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Details:   details,
+	})
+	if blocked {
+		perror.HandleError(w, http.StatusForbidden, "State-changing requests are blocked while impersonating", nil)
+		return false
+	}
+	return true
+}
+
+// blocksImpersonation reports whether the request is a write an impersonation session may not make.
+func blocksImpersonation(r *http.Request) bool {
+	exempt, _ := r.Context().Value(impersonationExemptKey{}).(bool)
+	return !exempt && !impersonatedWrites && !isSafeMethod(r.Method)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
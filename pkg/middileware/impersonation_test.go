@@ -0,0 +1,65 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBlocksImpersonation(t *testing.T) {
+	exempt := func(r *http.Request) *http.Request {
+		var exempted *http.Request
+		AllowDuringImpersonation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			exempted = r
+		})).ServeHTTP(httptest.NewRecorder(), r)
+		return exempted
+	}
+
+	tests := []struct {
+		name          string
+		request       *http.Request
+		allowWrites   bool
+		expectBlocked bool
+	}{
+		{name: "Read", request: httptest.NewRequest(http.MethodGet, "/api/course/list", nil), expectBlocked: false},
+		{name: "Head", request: httptest.NewRequest(http.MethodHead, "/api/course/list", nil), expectBlocked: false},
+		{name: "Create", request: httptest.NewRequest(http.MethodPost, "/api/course/", nil), expectBlocked: true},
+		{name: "Update", request: httptest.NewRequest(http.MethodPut, "/api/user/me", nil), expectBlocked: true},
+		{name: "Delete", request: httptest.NewRequest(http.MethodDelete, "/api/course/go", nil), expectBlocked: true},
+		{name: "StopImpersonation", request: exempt(httptest.NewRequest(http.MethodDelete, "/api/impersonation", nil)), expectBlocked: false},
+		{name: "WritesAllowed", request: httptest.NewRequest(http.MethodPost, "/api/course/", nil), allowWrites: true, expectBlocked: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			AllowImpersonatedWrites(test.allowWrites)
+			defer AllowImpersonatedWrites(false)
+			if blocked := blocksImpersonation(test.request); blocked != test.expectBlocked {
+				t.Fatalf("expected %v, got %v", test.expectBlocked, blocked)
+			}
+		})
+	}
+}
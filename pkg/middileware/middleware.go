@@ -25,9 +25,10 @@ SOFTWARE.
 package middleware
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 
-	"github.com/praromvik/praromvik/models/utils"
 	"github.com/praromvik/praromvik/pkg/auth"
 	perror "github.com/praromvik/praromvik/pkg/error"
 
@@ -44,6 +45,18 @@ func AddMiddlewares(router *chi.Mux) {
 
 func SecurityMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if plain, ok := auth.BearerToken(r); ok {
+			r, err := auth.AuthenticateToken(r, plain)
+			if err != nil {
+				perror.HandleError(w, http.StatusUnauthorized, "Invalid access token", err)
+				return
+			}
+			if !guardUserStatus(w, r) {
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
 		valid, err := auth.SessionValid(r)
 		if err != nil {
 			perror.HandleError(w, http.StatusUnauthorized, "Failed to validate session: "+err.Error(), err)
@@ -63,45 +76,63 @@ func SecurityMiddleware(next http.Handler) http.Handler {
 			perror.HandleError(w, http.StatusUnauthorized, "Unauthenticated session token", err)
 			return
 		}
+		if err := auth.TouchSession(r); err != nil {
+			log.Printf("failed to update session activity: %v", err)
+		}
+		if !guardImpersonation(w, r) || !guardUserStatus(w, r) {
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
-func AdminAccess(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		serveHTTPIfRoleMatched(next, writer, request, []utils.RoleType{utils.Admin})
-	})
-}
-
-func AdminOrModeratorAccess(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		serveHTTPIfRoleMatched(next, writer, request, []utils.RoleType{utils.Moderator, utils.Admin})
+// CSRFProtect rejects state-changing requests made with the session cookie unless they
+// echo the session's CSRF token in the X-CSRF-Token header.
+func CSRFProtect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ok, err := auth.VerifyCSRF(r)
+		if err != nil {
+			perror.HandleError(w, http.StatusForbidden, "Failed to verify CSRF token", err)
+			return
+		}
+		if !ok {
+			perror.HandleError(w, http.StatusForbidden, "Missing or invalid CSRF token", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
-func ModeratorAccess(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		serveHTTPIfRoleMatched(next, writer, request, []utils.RoleType{utils.Moderator})
-	})
+// RequireScope rejects personal access tokens which weren't granted the given scope.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auth.HasScope(r, scope) {
+				perror.HandleError(w, http.StatusForbidden, "", fmt.Errorf("access token lacks the '%s' scope", scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-func serveHTTPIfRoleMatched(next http.Handler, writer http.ResponseWriter, request *http.Request, roles []utils.RoleType) {
-	role, err := auth.GetSessionRole(request)
-	if err != nil {
-		perror.HandleError(writer, http.StatusUnauthorized, "Failed to retrieve role from session", err)
-		return
-	}
-	var authenticated bool
-	for _, val := range roles {
-		if val == role {
-			authenticated = true
-			break
+// SessionOnly rejects requests authenticated with a personal access token, or made while impersonating.
+func SessionOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.IsTokenRequest(r) {
+			perror.HandleError(w, http.StatusForbidden, "This action requires a signed in session", nil)
+			return
 		}
-	}
-	if !authenticated {
-		perror.HandleError(writer, http.StatusUnauthorized, "Insufficient privileges.", err)
-	}
-	next.ServeHTTP(writer, request)
+		if imp, err := auth.GetImpersonation(r); err != nil || imp != nil {
+			perror.HandleError(w, http.StatusForbidden, "This action isn't available while impersonating", err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 //func AddCourseIDToCtx(next http.Handler) http.Handler {
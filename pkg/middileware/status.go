@@ -0,0 +1,75 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/praromvik/praromvik/pkg/auth"
+	perror "github.com/praromvik/praromvik/pkg/error"
+)
+
+type passwordResetExemptKey struct{}
+
+// AllowDuringPasswordReset lets users who must reset their password reach a route,
+// i.e. the one changing it. It has to run before SecurityMiddleware.
+func AllowDuringPasswordReset(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), passwordResetExemptKey{}, true)))
+	})
+}
+
+// guardUserStatus rejects suspended & banned users, and users who must reset their password first.
+// It reports whether the request may go on.
+func guardUserStatus(w http.ResponseWriter, r *http.Request) bool {
+	info, err := auth.GetUserInfoFromSession(r)
+	if err != nil {
+		perror.HandleError(w, http.StatusUnauthorized, "Failed to get user info", err)
+		return false
+	}
+	status, resetRequired, err := auth.UserRestrictions(info.UUID)
+	if err != nil {
+		perror.HandleError(w, http.StatusInternalServerError, "Failed to check account status", err)
+		return false
+	}
+	if refusal := statusRefusal(r, status, resetRequired); refusal != "" {
+		perror.HandleError(w, http.StatusForbidden, refusal, nil)
+		return false
+	}
+	return true
+}
+
+// statusRefusal tells why a user with the given restrictions may not make the request, or nothing if they may.
+func statusRefusal(r *http.Request, status string, resetRequired bool) string {
+	if status != "" {
+		return "This account is " + status
+	}
+	exempt, _ := r.Context().Value(passwordResetExemptKey{}).(bool)
+	if resetRequired && !exempt {
+		return "A password reset is required, change your password first"
+	}
+	return ""
+}
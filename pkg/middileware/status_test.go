@@ -0,0 +1,62 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRefusal(t *testing.T) {
+	var passwordChange *http.Request
+	AllowDuringPasswordReset(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passwordChange = r
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/api/user/me/password", nil))
+	other := httptest.NewRequest(http.MethodGet, "/api/course/list", nil)
+
+	tests := []struct {
+		name          string
+		request       *http.Request
+		status        string
+		resetRequired bool
+		refused       bool
+	}{
+		{name: "Active", request: other, refused: false},
+		{name: "Suspended", request: other, status: "suspended", refused: true},
+		{name: "Banned", request: other, status: "banned", refused: true},
+		{name: "SuspendedChangingPassword", request: passwordChange, status: "suspended", refused: true},
+		{name: "ResetRequired", request: other, resetRequired: true, refused: true},
+		{name: "ResetRequiredChangingPassword", request: passwordChange, resetRequired: true, refused: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refusal := statusRefusal(test.request, test.status, test.resetRequired)
+			if refused := refusal != ""; refused != test.refused {
+				t.Fatalf("expected %v, got %v (%s)", test.refused, refused, refusal)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package openbadges
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"html"
+)
+
+// Keywords of the PNG text chunk a badge is baked in
+const (
+	KeywordV2 = "openbadges"          // the URL of a hosted assertion
+	KeywordV3 = "openbadgecredential" // a signed credential
+)
+
+const (
+	namespaceV2 = "http://openbadges.org"
+	namespaceV3 = "https://purl.imsglobal.org/ob/v3p0"
+)
+
+var ErrAlreadyBaked = errors.New("the image already carries a badge")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// BakePNG writes text in an iTXt chunk of the PNG under the keyword, right before its end.
+func BakePNG(image []byte, keyword string, text string) ([]byte, error) {
+	end := -1
+	err := chunks(image, func(offset int, kind string, data []byte) error {
+		if kind == "iTXt" && bytes.HasPrefix(data, append([]byte(keyword), 0)) {
+			return ErrAlreadyBaked
+		}
+		if kind == "IEND" {
+			end = offset
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if end < 0 {
+		return nil, errors.New("invalid PNG: no IEND chunk")
+	}
+
+	// keyword, null, not compressed, compression method, no language tag, null, no translated keyword, null, text
+	data := append([]byte(keyword), 0, 0, 0, 0, 0)
+	data = append(data, text...)
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], "iTXt")
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	baked := make([]byte, 0, len(image)+len(chunk))
+	baked = append(baked, image[:end]...)
+	baked = append(baked, chunk...)
+	return append(baked, image[end:]...), nil
+}
+
+// ReadPNG returns the text baked in the PNG under the keyword.
+func ReadPNG(image []byte, keyword string) (string, error) {
+	var text *string
+	err := chunks(image, func(offset int, kind string, data []byte) error {
+		prefix := append([]byte(keyword), 0)
+		if kind != "iTXt" || !bytes.HasPrefix(data, prefix) {
+			return nil
+		}
+		rest := data[len(prefix):]
+		if len(rest) < 2 || rest[0] != 0 {
+			return errors.New("compressed badges are not supported")
+		}
+		// skip the compression method, then the language tag & the translated keyword
+		parts := bytes.SplitN(rest[2:], []byte{0}, 3)
+		if len(parts) != 3 {
+			return errors.New("invalid iTXt chunk")
+		}
+		s := string(parts[2])
+		text = &s
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if text == nil {
+		return "", errors.New("no badge is baked in the image")
+	}
+	return *text, nil
+}
+
+// chunks walks the chunks of the PNG, checking their CRC.
+func chunks(image []byte, visit func(offset int, kind string, data []byte) error) error {
+	if !bytes.HasPrefix(image, pngSignature) {
+		return errors.New("not a PNG")
+	}
+	for offset := len(pngSignature); offset < len(image); {
+		if len(image)-offset < 12 {
+			return errors.New("invalid PNG: truncated chunk")
+		}
+		length := int(binary.BigEndian.Uint32(image[offset:]))
+		if length > len(image)-offset-12 {
+			return errors.New("invalid PNG: truncated chunk")
+		}
+		kind := string(image[offset+4 : offset+8])
+		data := image[offset+8 : offset+8+length]
+		if crc := binary.BigEndian.Uint32(image[offset+8+length:]); crc != crc32.ChecksumIEEE(image[offset+4:offset+8+length]) {
+			return fmt.Errorf("invalid PNG: bad CRC of the %s chunk", kind)
+		}
+		if err := visit(offset, kind, data); err != nil {
+			return err
+		}
+		offset += 12 + length
+	}
+	return nil
+}
+
+// BakeSVGAssertion bakes the URL of a hosted 2.0 assertion in the SVG.
+func BakeSVGAssertion(svg []byte, url string) ([]byte, error) {
+	return bakeSVG(svg, namespaceV2, fmt.Sprintf(`<openbadges:assertion verify="%s"></openbadges:assertion>`, html.EscapeString(url)))
+}
+
+// BakeSVGCredential bakes a signed 3.0 credential in the SVG.
+func BakeSVGCredential(svg []byte, signed string) ([]byte, error) {
+	return bakeSVG(svg, namespaceV3, fmt.Sprintf(`<openbadges:credential verify="%s"></openbadges:credential>`, html.EscapeString(signed)))
+}
+
+// bakeSVG declares the namespace on the root element & puts the element first in it.
+func bakeSVG(svg []byte, namespace string, element string) ([]byte, error) {
+	if bytes.Contains(svg, []byte("xmlns:openbadges=")) {
+		return nil, ErrAlreadyBaked
+	}
+	start := bytes.Index(svg, []byte("<svg"))
+	if start < 0 {
+		return nil, errors.New("not an SVG")
+	}
+	end := bytes.IndexByte(svg[start:], '>')
+	if end < 0 {
+		return nil, errors.New("invalid SVG")
+	}
+	end += start
+	if svg[end-1] == '/' {
+		return nil, errors.New("the SVG is empty")
+	}
+	var b bytes.Buffer
+	b.Write(svg[:end])
+	fmt.Fprintf(&b, ` xmlns:openbadges="%s">`, namespace)
+	b.WriteString(element)
+	b.Write(svg[end+1:])
+	return b.Bytes(), nil
+}
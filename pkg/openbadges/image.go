@@ -0,0 +1,165 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package openbadges
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// size of the badge images, in pixels
+const size = 256
+
+// samples per pixel side, smoothing the edges
+const samples = 4
+
+// hue picks the color of a badge from its seed, so that every course keeps its own.
+func hue(seed string) float64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(seed))
+	return float64(h.Sum32() % 360)
+}
+
+// RenderPNG draws the badge of the seed: a medal with a star.
+func RenderPNG(seed string) ([]byte, error) {
+	outer := hsl(hue(seed), 0.55, 0.40)
+	inner := hsl(hue(seed), 0.55, 0.30)
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	star := starPoints(size/2, size/2, 60, 25)
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var r, g, b, a float64
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					px := float64(x) + (float64(sx)+0.5)/samples
+					py := float64(y) + (float64(sy)+0.5)/samples
+					d := math.Hypot(px-size/2, py-size/2)
+					var c color.RGBA
+					switch {
+					case d > 124:
+						continue
+					case inPolygon(px, py, star):
+						c = white
+					case d > 104:
+						c = outer
+					case d >= 98:
+						c = white
+					default:
+						c = inner
+					}
+					r, g, b, a = r+float64(c.R), g+float64(c.G), b+float64(c.B), a+255
+				}
+			}
+			n := float64(samples * samples)
+			if a > 0 {
+				// colors are premultiplied by their coverage
+				img.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+			}
+		}
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// RenderSVG draws the badge of the seed, with the title on a ribbon.
+func RenderSVG(seed string, title string) []byte {
+	h := hue(seed)
+	var points []string
+	for _, p := range starPoints(size/2, size/2-12, 50, 21) {
+		points = append(points, fmt.Sprintf("%.1f,%.1f", p[0], p[1]))
+	}
+	if runes := []rune(title); len(runes) > 28 {
+		title = string(runes[:27]) + "…"
+	}
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[1]d" viewBox="0 0 %[1]d %[1]d">`+
+		`<circle cx="128" cy="128" r="124" fill="hsl(%[2]g,55%%,40%%)"/>`+
+		`<circle cx="128" cy="128" r="101" fill="none" stroke="#fff" stroke-width="6"/>`+
+		`<circle cx="128" cy="128" r="98" fill="hsl(%[2]g,55%%,30%%)"/>`+
+		`<polygon points="%[3]s" fill="#fff"/>`+
+		`<rect x="8" y="168" width="240" height="34" rx="4" fill="#fff"/>`+
+		`<text x="128" y="190" font-family="Helvetica, Arial, sans-serif" font-size="14" font-weight="bold" text-anchor="middle" fill="hsl(%[2]g,55%%,30%%)">%[4]s</text>`+
+		`</svg>`, size, h, strings.Join(points, " "), html.EscapeString(title)))
+}
+
+// starPoints returns the corners of a five-pointed star pointing up.
+func starPoints(cx float64, cy float64, outer float64, inner float64) [][2]float64 {
+	points := make([][2]float64, 10)
+	for i := range points {
+		radius := outer
+		if i%2 == 1 {
+			radius = inner
+		}
+		angle := -math.Pi/2 + float64(i)*math.Pi/5
+		points[i] = [2]float64{cx + radius*math.Cos(angle), cy + radius*math.Sin(angle)}
+	}
+	return points
+}
+
+// inPolygon casts a ray to the right of the point & counts the edges it crosses.
+func inPolygon(x float64, y float64, polygon [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a[1] > y) != (b[1] > y) && x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// hsl converts a color given by hue (degrees), saturation & lightness (0 to 1).
+func hsl(h float64, s float64, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 255}
+}
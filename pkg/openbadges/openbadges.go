@@ -0,0 +1,245 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package openbadges describes the achievements of students as Open Badges: 2.0 assertions verified by
+// hosting them, and 3.0 credentials signed as VC-JWTs. Badge images carry them baked in (see bake.go).
+package openbadges
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	ContextV2 = "https://w3id.org/openbadges/v2"
+	ContextVC = "https://www.w3.org/ns/credentials/v2"
+	ContextV3 = "https://purl.imsglobal.org/spec/ob/v3p0/context-3.0.3.json"
+)
+
+// Issuer is who awards the badges.
+type Issuer struct {
+	ID    string // URL of the hosted profile
+	Name  string
+	URL   string
+	Email string
+}
+
+// Achievement is what a badge is awarded for.
+type Achievement struct {
+	ID          string // URL of the hosted badge class
+	Name        string
+	Description string
+	Criteria    string
+	Image       string // URL
+}
+
+// Award is an achievement earned by a recipient, identified by the IdentityHash of their email.
+type Award struct {
+	ID          string // URL of the hosted assertion or credential
+	Identity    string
+	Salt        string
+	IssuedOn    time.Time
+	Evidence    string // URL
+	Achievement Achievement
+	Issuer      Issuer
+}
+
+// IdentityHash hashes the email of a recipient, so that badges can be matched to it without revealing it.
+func IdentityHash(email string, salt string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email)) + salt))
+	return "sha256$" + hex.EncodeToString(sum[:])
+}
+
+// Open Badges 2.0
+
+type Profile struct {
+	Context string `json:"@context"`
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Email   string `json:"email,omitempty"`
+}
+
+type BadgeClass struct {
+	Context     string   `json:"@context"`
+	Type        string   `json:"type"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Image       string   `json:"image"`
+	Criteria    Criteria `json:"criteria"`
+	Issuer      string   `json:"issuer"`
+}
+
+type Criteria struct {
+	Narrative string `json:"narrative"`
+}
+
+type Assertion struct {
+	Context      string         `json:"@context"`
+	Type         string         `json:"type"`
+	ID           string         `json:"id"`
+	Recipient    IdentityObject `json:"recipient"`
+	Badge        string         `json:"badge"`
+	Verification Verification   `json:"verification"`
+	IssuedOn     time.Time      `json:"issuedOn"`
+	Evidence     string         `json:"evidence,omitempty"`
+}
+
+type IdentityObject struct {
+	Type     string `json:"type"`
+	Hashed   bool   `json:"hashed"`
+	Salt     string `json:"salt"`
+	Identity string `json:"identity"`
+}
+
+type Verification struct {
+	Type string `json:"type"`
+}
+
+func (i Issuer) ProfileV2() Profile {
+	return Profile{Context: ContextV2, Type: "Issuer", ID: i.ID, Name: i.Name, URL: i.URL, Email: i.Email}
+}
+
+func (a Achievement) BadgeClassV2(issuer Issuer) BadgeClass {
+	return BadgeClass{
+		Context:     ContextV2,
+		Type:        "BadgeClass",
+		ID:          a.ID,
+		Name:        a.Name,
+		Description: a.Description,
+		Image:       a.Image,
+		Criteria:    Criteria{Narrative: a.Criteria},
+		Issuer:      issuer.ID,
+	}
+}
+
+// AssertionV2 is the hosted assertion of the award: it is verified by fetching it from its ID.
+func (a Award) AssertionV2() Assertion {
+	return Assertion{
+		Context:      ContextV2,
+		Type:         "Assertion",
+		ID:           a.ID,
+		Recipient:    IdentityObject{Type: "email", Hashed: true, Salt: a.Salt, Identity: a.Identity},
+		Badge:        a.Achievement.ID,
+		Verification: Verification{Type: "hosted"},
+		IssuedOn:     a.IssuedOn.UTC(),
+		Evidence:     a.Evidence,
+	}
+}
+
+// Open Badges 3.0
+
+type Credential struct {
+	Context           []string           `json:"@context"`
+	ID                string             `json:"id"`
+	Type              []string           `json:"type"`
+	Issuer            ProfileV3          `json:"issuer"`
+	ValidFrom         time.Time          `json:"validFrom"`
+	Name              string             `json:"name"`
+	CredentialSubject AchievementSubject `json:"credentialSubject"`
+	Evidence          []Evidence         `json:"evidence,omitempty"`
+}
+
+type ProfileV3 struct {
+	ID    string   `json:"id"`
+	Type  []string `json:"type"`
+	Name  string   `json:"name"`
+	URL   string   `json:"url,omitempty"`
+	Email string   `json:"email,omitempty"`
+}
+
+type AchievementSubject struct {
+	Type        []string           `json:"type"`
+	Identifier  []IdentityObjectV3 `json:"identifier"`
+	Achievement AchievementV3      `json:"achievement"`
+}
+
+type IdentityObjectV3 struct {
+	Type         string `json:"type"`
+	IdentityHash string `json:"identityHash"`
+	IdentityType string `json:"identityType"`
+	Hashed       bool   `json:"hashed"`
+	Salt         string `json:"salt"`
+}
+
+type AchievementV3 struct {
+	ID          string   `json:"id"`
+	Type        []string `json:"type"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Criteria    Criteria `json:"criteria"`
+	Image       *Image   `json:"image,omitempty"`
+}
+
+type Image struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type Evidence struct {
+	ID   string   `json:"id"`
+	Type []string `json:"type"`
+	Name string   `json:"name,omitempty"`
+}
+
+// CredentialV3 is the award as an OpenBadgeCredential, to be signed with SignCredential.
+func (a Award) CredentialV3() Credential {
+	achievement := AchievementV3{
+		ID:          a.Achievement.ID,
+		Type:        []string{"Achievement"},
+		Name:        a.Achievement.Name,
+		Description: a.Achievement.Description,
+		Criteria:    Criteria{Narrative: a.Achievement.Criteria},
+	}
+	if a.Achievement.Image != "" {
+		achievement.Image = &Image{ID: a.Achievement.Image, Type: "Image"}
+	}
+	c := Credential{
+		Context:   []string{ContextVC, ContextV3},
+		ID:        a.ID,
+		Type:      []string{"VerifiableCredential", "OpenBadgeCredential"},
+		Issuer:    ProfileV3{ID: a.Issuer.ID, Type: []string{"Profile"}, Name: a.Issuer.Name, URL: a.Issuer.URL, Email: a.Issuer.Email},
+		ValidFrom: a.IssuedOn.UTC(),
+		Name:      a.Achievement.Name,
+		CredentialSubject: AchievementSubject{
+			Type: []string{"AchievementSubject"},
+			Identifier: []IdentityObjectV3{{
+				Type:         "IdentityObject",
+				IdentityHash: a.Identity,
+				IdentityType: "emailAddress",
+				Hashed:       true,
+				Salt:         a.Salt,
+			}},
+			Achievement: achievement,
+		},
+	}
+	if a.Evidence != "" {
+		c.Evidence = []Evidence{{ID: a.Evidence, Type: []string{"Evidence"}, Name: "Certificate"}}
+	}
+	return c
+}
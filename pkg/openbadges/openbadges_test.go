@@ -0,0 +1,192 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package openbadges
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func award() Award {
+	issuer := Issuer{ID: "https://praromvik.com/api/badges/issuer", Name: "Praromvik", URL: "https://praromvik.com"}
+	return Award{
+		ID:       "https://praromvik.com/api/badges/assertions/7XQ2-M9KD-3HVA-P0TE",
+		Identity: IdentityHash("Jane@Example.com", "7XQ2-M9KD-3HVA-P0TE"),
+		Salt:     "7XQ2-M9KD-3HVA-P0TE",
+		IssuedOn: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Evidence: "https://praromvik.com/verify/7XQ2-M9KD-3HVA-P0TE",
+		Achievement: Achievement{
+			ID:       "https://praromvik.com/api/badges/courses/advanced-golang",
+			Name:     "Advanced Golang",
+			Criteria: "Complete every content of the course.",
+			Image:    "https://praromvik.com/api/badges/courses/advanced-golang/image.png",
+		},
+		Issuer: issuer,
+	}
+}
+
+func TestIdentityHash(t *testing.T) {
+	sum := sha256.Sum256([]byte("jane@example.com" + "salt"))
+	expected := "sha256$" + hex.EncodeToString(sum[:])
+	tests := []struct {
+		name  string
+		email string
+	}{
+		{name: "AsIs", email: "jane@example.com"},
+		{name: "MixedCase", email: "Jane@Example.com"},
+		{name: "Spaces", email: " jane@example.com "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IdentityHash(test.email, "salt"); got != expected {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestAssertionV2(t *testing.T) {
+	a := award().AssertionV2()
+	if a.Recipient.Identity != IdentityHash("jane@example.com", a.Recipient.Salt) || !a.Recipient.Hashed {
+		t.Fatalf("expected a hashed recipient, got %+v", a.Recipient)
+	}
+	if a.Badge != award().Achievement.ID || a.Verification.Type != "hosted" {
+		t.Fatalf("expected a hosted assertion of the badge class, got %+v", a)
+	}
+	if strings.Contains(a.Recipient.Identity, "jane") {
+		t.Fatalf("expected the email to be hidden, got %v", a.Recipient.Identity)
+	}
+}
+
+func TestSignCredential(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	signed, err := SignCredential(award().CredentialV3(), private, "https://praromvik.com/api/ledger/key")
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	parts := strings.Split(signed, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+
+	tests := []struct {
+		name   string
+		signed string
+		key    ed25519.PublicKey
+		valid  bool
+	}{
+		{name: "Valid", signed: signed, key: public, valid: true},
+		{name: "OtherKey", signed: signed, key: other, valid: false},
+		{name: "Tampered", signed: tampered, key: public, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := VerifyCredential(test.signed, test.key)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, err)
+			}
+			if test.valid && c.CredentialSubject.Achievement.Name != "Advanced Golang" {
+				t.Fatalf("expected %v, got %v", "Advanced Golang", c.CredentialSubject.Achievement.Name)
+			}
+		})
+	}
+}
+
+func TestBakePNG(t *testing.T) {
+	image, err := RenderPNG("advanced-golang")
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	url := award().ID
+	baked, err := BakePNG(image, KeywordV2, url)
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	if _, err := png.Decode(bytes.NewReader(baked)); err != nil {
+		t.Fatalf("expected a valid PNG, got %v", err)
+	}
+	if got, err := ReadPNG(baked, KeywordV2); got != url {
+		t.Fatalf("expected %v, got %v (%v)", url, got, err)
+	}
+	if _, err := ReadPNG(baked, KeywordV3); err == nil {
+		t.Fatalf("expected no %s badge", KeywordV3)
+	}
+	if _, err := BakePNG(baked, KeywordV2, url); !errors.Is(err, ErrAlreadyBaked) {
+		t.Fatalf("expected %v, got %v", ErrAlreadyBaked, err)
+	}
+	corrupted := append([]byte{}, baked...)
+	corrupted[len(corrupted)-20] ^= 1
+	if _, err := ReadPNG(corrupted, KeywordV2); err == nil {
+		t.Fatalf("expected a CRC error")
+	}
+}
+
+func TestBakeSVG(t *testing.T) {
+	image := RenderSVG("advanced-golang", `Go <Generics> & "Runtime"`)
+	tests := []struct {
+		name     string
+		bake     func([]byte) ([]byte, error)
+		expected string
+	}{
+		{name: "Assertion", bake: func(svg []byte) ([]byte, error) { return BakeSVGAssertion(svg, award().ID+"?a=1&b=2") },
+			expected: `<openbadges:assertion verify="https://praromvik.com/api/badges/assertions/7XQ2-M9KD-3HVA-P0TE?a=1&amp;b=2">`},
+		{name: "Credential", bake: func(svg []byte) ([]byte, error) { return BakeSVGCredential(svg, "header.payload.signature") },
+			expected: `<openbadges:credential verify="header.payload.signature">`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			baked, err := test.bake(image)
+			if err != nil {
+				t.Fatalf("expected %v, got %v", nil, err)
+			}
+			if !bytes.Contains(baked, []byte(test.expected)) {
+				t.Fatalf("expected %q in %s", test.expected, baked)
+			}
+			decoder := xml.NewDecoder(bytes.NewReader(baked))
+			for {
+				if _, err := decoder.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("expected well-formed XML, got %v", err)
+				}
+			}
+			if _, err := test.bake(baked); !errors.Is(err, ErrAlreadyBaked) {
+				t.Fatalf("expected %v, got %v", ErrAlreadyBaked, err)
+			}
+		})
+	}
+}
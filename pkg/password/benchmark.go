@@ -0,0 +1,57 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package password
+
+import "time"
+
+// Measurement is how long hashing took with some parameters.
+type Measurement struct {
+	Params   Params
+	Duration time.Duration
+}
+
+// Benchmark measures argon2id with the given memory & parallelism, raising the iterations until a hash
+// takes at least target. The last measurement is the recommended one.
+func Benchmark(memory uint32, parallelism uint8, target time.Duration) ([]Measurement, error) {
+	p := DefaultParams
+	p.Memory, p.Parallelism, p.Iterations = memory, parallelism, 1
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result []Measurement
+	for ; p.Iterations <= 64; p.Iterations++ {
+		start := time.Now()
+		if _, err := p.Hash("benchmark-password"); err != nil {
+			return nil, err
+		}
+		m := Measurement{Params: p, Duration: time.Since(start)}
+		result = append(result, m)
+		if m.Duration >= target {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package password
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config is the password hashing & policy setup of the server.
+type Config struct {
+	Argon2       Params `json:"argon2"`
+	Policy       Policy `json:"policy"`
+	BreachedList string `json:"breachedList"`
+}
+
+// LoadConfig reads the config from a JSON file. Fields left out keep their defaults.
+// Without a file, the defaults are returned.
+func LoadConfig(path string) (Config, error) {
+	c := Config{Argon2: DefaultParams, Policy: DefaultPolicy}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Config{}, fmt.Errorf("invalid password config: %w", err)
+	}
+	if err := c.Argon2.Validate(); err != nil {
+		return Config{}, err
+	}
+	if c.BreachedList != "" {
+		if err := c.Policy.LoadBreachedList(c.BreachedList); err != nil {
+			return Config{}, fmt.Errorf("failed to load breached password list: %w", err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,139 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keep the tests fast, they aren't meant for production.
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestVerify(t *testing.T) {
+	argon, err := testParams.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		expected bool
+	}{
+		{name: "Argon2id", hash: argon, password: "correct horse", expected: true},
+		{name: "Argon2idWrongPassword", hash: argon, password: "battery staple", expected: false},
+		{name: "Bcrypt", hash: string(legacy), password: "correct horse", expected: true},
+		{name: "BcryptWrongPassword", hash: string(legacy), password: "battery staple", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Verify(test.hash, test.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	if _, err := Verify("plain-text", "plain-text"); err == nil {
+		t.Fatalf("expected an error for an unknown hash format")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := testParams.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := testParams
+	stronger.Iterations = 2
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		params   Params
+		hash     string
+		expected bool
+	}{
+		{name: "Current", params: testParams, hash: current, expected: false},
+		{name: "OutdatedParams", params: stronger, hash: current, expected: true},
+		{name: "Bcrypt", params: testParams, hash: string(legacy), expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.params.NeedsRehash(test.hash); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	// "password1" in plain text & "qwertyuiop" as a SHA-1 with a count.
+	content := "# breached\npassword1\nB0399D2029F64D445BD131FFAA399A42D2F8E7DC:3810\n"
+	if err := os.WriteFile(list, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy := DefaultPolicy
+	if err := policy.LoadBreachedList(list); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{name: "Valid", password: "correct horse battery", valid: true},
+		{name: "TooShort", password: "short", valid: false},
+		{name: "MultiByteLength", password: "পাসওয়ার্ড", valid: true},
+		{name: "SameAsUserName", password: "Student-One", valid: false},
+		{name: "BreachedPlain", password: "password1", valid: false},
+		{name: "BreachedHash", password: "qwertyuiop", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := policy.Check(test.password, "student-one")
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid %v, got error %v", test.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package pdf writes single page PDF documents with text & simple shapes, using the standard fonts every
+// reader ships with, so no font has to be embedded. Text is WinAnsi (Latin-1) encoded.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Font is one of the standard fonts.
+type Font string
+
+const (
+	Helvetica     Font = "Helvetica"
+	HelveticaBold Font = "Helvetica-Bold"
+)
+
+var fontNames = map[Font]string{Helvetica: "F1", HelveticaBold: "F2"}
+
+// Page sizes in points, landscape.
+const (
+	A4Width  = 841.89
+	A4Height = 595.28
+)
+
+// Color is an RGB color, every component from 0 to 1.
+type Color [3]float64
+
+var Black = Color{0, 0, 0}
+
+// Info is the metadata of the document.
+type Info struct {
+	Title   string
+	Author  string
+	Subject string
+	Created time.Time
+}
+
+// Document is a single page under construction. The origin is the bottom left corner of the page.
+type Document struct {
+	Width  float64
+	Height float64
+	Info   Info
+	ops    bytes.Buffer
+}
+
+func New(width float64, height float64) *Document {
+	return &Document{Width: width, Height: height}
+}
+
+// Text writes s with its baseline starting at (x, y).
+func (d *Document) Text(x float64, y float64, font Font, size float64, color Color, s string) error {
+	name, ok := fontNames[font]
+	if !ok {
+		return fmt.Errorf("unsupported font %q", font)
+	}
+	fmt.Fprintf(&d.ops, "BT %s rg /%s %s Tf %s %s Td (%s) Tj ET\n",
+		color.String(), name, num(size), num(x), num(y), escape(s))
+	return nil
+}
+
+// Line strokes a line from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1 float64, y1 float64, x2 float64, y2 float64, width float64, color Color) {
+	fmt.Fprintf(&d.ops, "%s RG %s w %s %s m %s %s l S\n",
+		color.String(), num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect strokes a rectangle whose bottom left corner is (x, y).
+func (d *Document) Rect(x float64, y float64, w float64, h float64, width float64, color Color) {
+	fmt.Fprintf(&d.ops, "%s RG %s w %s %s %s %s re S\n",
+		color.String(), num(width), num(x), num(y), num(w), num(h))
+}
+
+// Bytes writes out the document.
+func (d *Document) Bytes() []byte {
+	content := d.ops.Bytes()
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>",
+			num(d.Width), num(d.Height)),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		d.info(),
+	}
+
+	var out bytes.Buffer
+	// The comment of high bytes tells transfer tools the file is binary.
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	return out.Bytes()
+}
+
+func (d *Document) info() string {
+	var b strings.Builder
+	b.WriteString("<< /Producer (Praromvik)")
+	for _, field := range []struct{ key, value string }{
+		{"Title", d.Info.Title}, {"Author", d.Info.Author}, {"Subject", d.Info.Subject},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, " /%s (%s)", field.key, escape(field.value))
+		}
+	}
+	if !d.Info.Created.IsZero() {
+		fmt.Fprintf(&b, " /CreationDate (D:%sZ)", d.Info.Created.UTC().Format("20060102150405"))
+	}
+	b.WriteString(" >>")
+	return b.String()
+}
+
+func (c Color) String() string {
+	return fmt.Sprintf("%s %s %s", num(c[0]), num(c[1]), num(c[2]))
+}
+
+// num formats a number the short way PDF expects, without exponents.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "Plain", text: "Advanced Golang", expected: "Advanced Golang"},
+		{name: "Parentheses", text: "Go (2024)", expected: `Go \(2024\)`},
+		{name: "Backslash", text: `a\b`, expected: `a\\b`},
+		{name: "Latin1", text: "José", expected: `Jos\351`},
+		{name: "WinAnsi", text: "“Go”", expected: `\223Go\224`},
+		{name: "Unsupported", text: "আ", expected: "?"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := escape(test.text); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		font     Font
+		text     string
+		expected float64
+	}{
+		{name: "Helvetica", font: Helvetica, text: "Go", expected: (778 + 556) * 12 / 1000.0},
+		{name: "Bold", font: HelveticaBold, text: "Go", expected: (778 + 611) * 12 / 1000.0},
+		{name: "Accented", font: Helvetica, text: "é", expected: 556 * 12 / 1000.0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Width(test.font, 12, test.text); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestBytes(t *testing.T) {
+	d := New(A4Width, A4Height)
+	d.Info = Info{Title: "Certificate", Created: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	d.Rect(20, 20, A4Width-40, A4Height-40, 2, Black)
+	if err := d.Text(100, 300, HelveticaBold, 24, Black, "Jane (Doe)"); err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	if err := d.Text(100, 300, Font("Comic-Sans"), 24, Black, "x"); err == nil {
+		t.Fatalf("expected an error for an unsupported font")
+	}
+	out := d.Bytes()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("expected a PDF header & trailer, got %q", out)
+	}
+	for _, expected := range []string{`(Jane \(Doe\)) Tj`, "/CreationDate (D:20240501100000Z)", "/MediaBox [0 0 841.89 595.28]"} {
+		if !bytes.Contains(out, []byte(expected)) {
+			t.Fatalf("expected %q in %q", expected, out)
+		}
+	}
+
+	// Every cross-reference entry must point at the start of its object.
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if startxref == nil {
+		t.Fatalf("expected a startxref, got %q", out)
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("expected the xref at %d, got %q", xref, out[xref:])
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) != 7 {
+		t.Fatalf("expected %v, got %v", 7, len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if expected := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(out[offset:], []byte(expected)) {
+			t.Fatalf("expected %q at %d, got %q", expected, offset, out[offset:offset+10])
+		}
+	}
+}
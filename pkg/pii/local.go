@@ -0,0 +1,120 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pii
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LocalKeyProvider keeps the keys in a JSON file. It is meant for development,
+// production should keep its KEKs in a key management service.
+//
+//	{
+//	  "current": "k2",
+//	  "keys": {"k1": "<base64>", "k2": "<base64>"},
+//	  "indexKey": "<base64>"
+//	}
+type LocalKeyProvider struct {
+	Current     string            `json:"current"`
+	Keys        map[string][]byte `json:"keys"`
+	IndexSecret []byte            `json:"indexKey"`
+}
+
+var _ KeyProvider = &LocalKeyProvider{}
+
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p LocalKeyProvider
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid key file: %w", err)
+	}
+	if len(p.Keys[p.Current]) != 32 {
+		return nil, fmt.Errorf("invalid key file: the current key '%s' must be 32 bytes long", p.Current)
+	}
+	if len(p.IndexSecret) < 32 {
+		return nil, errors.New("invalid key file: the index key must be at least 32 bytes long")
+	}
+	return &p, nil
+}
+
+// AddKey generates a new KEK & makes it the current one. Values encrypted with
+// the older keys stay readable until they are re-encrypted.
+func (p *LocalKeyProvider) AddKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	if p.Keys == nil {
+		p.Keys = map[string][]byte{}
+	}
+	if p.IndexSecret == nil {
+		p.IndexSecret = make([]byte, 32)
+		if _, err := rand.Read(p.IndexSecret); err != nil {
+			return "", err
+		}
+	}
+	id := ""
+	for n := len(p.Keys) + 1; id == "" || p.Keys[id] != nil; n++ {
+		id = fmt.Sprintf("k%d", n)
+	}
+	p.Keys[id], p.Current = key, id
+	return id, nil
+}
+
+func (p *LocalKeyProvider) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (p *LocalKeyProvider) WrapKey(dek []byte) (string, []byte, error) {
+	wrapped, err := seal(p.Keys[p.Current], dek)
+	return p.Current, wrapped, err
+}
+
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key '%s'", keyID)
+	}
+	return open(kek, wrapped)
+}
+
+func (p *LocalKeyProvider) CurrentKeyID() string {
+	return p.Current
+}
+
+func (p *LocalKeyProvider) IndexKey() []byte {
+	return p.IndexSecret
+}
@@ -0,0 +1,171 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pii
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	provider := &LocalKeyProvider{}
+	if _, err := provider.AddKey(); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCipher(provider)
+
+	encrypted, err := c.Encrypt("student@praromvik.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encrypted, "student") {
+		t.Fatalf("expected the plaintext to be hidden, got %s", encrypted)
+	}
+	again, err := c.Encrypt("student@praromvik.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == encrypted {
+		t.Fatalf("expected every encryption to use a fresh data key")
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "Encrypted", value: encrypted, expected: "student@praromvik.com"},
+		{name: "Legacy", value: "plain@praromvik.com", expected: "plain@praromvik.com"},
+		{name: "Empty", value: "", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := c.Decrypt(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	if _, err := c.Decrypt(encrypted[:len(encrypted)-4] + "AAAA"); err == nil {
+		t.Fatalf("expected tampered values to fail")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	provider := &LocalKeyProvider{}
+	if _, err := provider.AddKey(); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	old := NewCipher(provider)
+	encrypted, err := old.Encrypt("01700000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := old.BlindIndex("01700000000")
+
+	rotated, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotated.AddKey(); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCipher(rotated)
+	if !c.NeedsRotation(encrypted) {
+		t.Fatalf("expected a value of the old key to need rotation")
+	}
+	got, err := c.Decrypt(encrypted)
+	if err != nil || got != "01700000000" {
+		t.Fatalf("expected the old key to stay readable, got %q, %v", got, err)
+	}
+	reencrypted, err := c.Encrypt(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.NeedsRotation(reencrypted) {
+		t.Fatalf("expected a value of the current key not to need rotation")
+	}
+	if c.BlindIndex("01700000000") != index {
+		t.Fatalf("expected the blind index to survive key rotation")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	provider := &LocalKeyProvider{}
+	if _, err := provider.AddKey(); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCipher(provider)
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{name: "Same", a: "a@praromvik.com", b: "a@praromvik.com", expected: true},
+		{name: "Case", a: "A@Praromvik.com", b: "a@praromvik.com", expected: true},
+		{name: "Spaces", a: " a@praromvik.com ", b: "a@praromvik.com", expected: true},
+		{name: "Different", a: "a@praromvik.com", b: "b@praromvik.com", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := c.BlindIndex(test.a) == c.BlindIndex(test.b); got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckPlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{name: "Email", value: "student@praromvik.com", valid: true},
+		{name: "Empty", value: "", valid: true},
+		{name: "Prefixed", value: prefix + "student@praromvik.com", valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckPlain(test.value)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v", test.valid, valid)
+			}
+			if err != nil && !errors.Is(err, ErrReserved) {
+				t.Fatalf("expected %v, got %v", ErrReserved, err)
+			}
+		})
+	}
+}
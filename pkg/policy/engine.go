@@ -0,0 +1,120 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package policy
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Engine evaluates the policy read from a file, and picks up changes to it while running.
+// Without a file, it evaluates the default policy.
+type Engine struct {
+	path string
+
+	mu      sync.RWMutex
+	policy  *Policy
+	modTime time.Time
+}
+
+func NewEngine(path string) (*Engine, error) {
+	e := &Engine{path: path, policy: Default()}
+	if path == "" {
+		return e, nil
+	}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Engine) Decide(subject Subject, action string, resource Resource) Decision {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policy.Decide(subject, action, resource)
+}
+
+// Reload reads the policy file again. On failure, the current policy stays in effect.
+func (e *Engine) Reload() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policy, e.modTime = p, info.ModTime()
+	return nil
+}
+
+// Watch reloads the policy whenever the file changes, until the context is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	if e.path == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, changed := e.changed()
+			if !changed {
+				continue
+			}
+			if err := e.Reload(); err != nil {
+				log.Printf("failed to reload policy %s, keeping the current one: %v", e.path, err)
+				// Don't retry until the file changes again.
+				e.mu.Lock()
+				e.modTime = modTime
+				e.mu.Unlock()
+				continue
+			}
+			log.Printf("reloaded policy %s", e.path)
+		}
+	}
+}
+
+func (e *Engine) changed() (time.Time, bool) {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return info.ModTime(), !info.ModTime().Equal(e.modTime)
+}
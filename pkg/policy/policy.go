@@ -0,0 +1,162 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package policy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Conditions a rule can put on the resource, relative to the subject
+const (
+	Self       = "self"       // the resource is the subject itself
+	Owner      = "owner"      // the subject owns the resource
+	Instructor = "instructor" // the subject instructs the course of the resource
+	Moderator  = "moderator"  // the subject moderates the course of the resource
+	Staff      = "staff"      // the subject instructs or moderates the course of the resource
+	Enrolled   = "enrolled"   // the subject is enrolled in the course of the resource
+)
+
+// Any matches every role, action or resource kind.
+const Any = "*"
+
+//go:embed default_policy.json
+var defaultPolicy []byte
+
+// Rule allows the listed roles to perform the listed actions on a kind of resource.
+// If there are conditions, every one of them must hold as well.
+type Rule struct {
+	Roles      []string `json:"roles"`
+	Resource   string   `json:"resource"`
+	Actions    []string `json:"actions"`
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+type Subject struct {
+	Name  string
+	Roles []string
+}
+
+// Resource holds the attributes of the resource that conditions are evaluated against.
+type Resource struct {
+	Kind        string
+	ID          string
+	Owner       string
+	Instructors []string
+	Moderators  []string
+	Students    []string
+}
+
+// Decision is the outcome of an evaluation. Rule is the rule which allowed it, if any.
+type Decision struct {
+	Allowed bool
+	Rule    *Rule
+}
+
+// Parse reads a policy, rejecting rules which could never be evaluated.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	for i, rule := range p.Rules {
+		if len(rule.Roles) == 0 || len(rule.Actions) == 0 || rule.Resource == "" {
+			return nil, fmt.Errorf("rule %d: roles, resource & actions are required", i)
+		}
+		for _, condition := range rule.Conditions {
+			if !slices.Contains([]string{Self, Owner, Instructor, Moderator, Staff, Enrolled}, condition) {
+				return nil, fmt.Errorf("rule %d: unknown condition '%s'", i, condition)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// Default returns the policy shipped with the server.
+func Default() *Policy {
+	p, err := Parse(defaultPolicy)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default policy: %v", err))
+	}
+	return p
+}
+
+// Decide evaluates the policy. Everything which isn't explicitly allowed is denied.
+func (p *Policy) Decide(subject Subject, action string, resource Resource) Decision {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.matches(subject, action, resource) {
+			return Decision{Allowed: true, Rule: rule}
+		}
+	}
+	return Decision{}
+}
+
+func (r *Rule) matches(subject Subject, action string, resource Resource) bool {
+	if r.Resource != Any && r.Resource != resource.Kind {
+		return false
+	}
+	if !slices.Contains(r.Actions, Any) && !slices.Contains(r.Actions, action) {
+		return false
+	}
+	if !slices.ContainsFunc(subject.Roles, func(role string) bool {
+		return role != "" && (slices.Contains(r.Roles, Any) || slices.Contains(r.Roles, role))
+	}) {
+		return false
+	}
+	for _, condition := range r.Conditions {
+		if !holds(condition, subject, resource) {
+			return false
+		}
+	}
+	return true
+}
+
+func holds(condition string, subject Subject, resource Resource) bool {
+	if subject.Name == "" {
+		return false
+	}
+	switch condition {
+	case Self:
+		return resource.ID == subject.Name
+	case Owner:
+		return resource.Owner == subject.Name
+	case Instructor:
+		return slices.Contains(resource.Instructors, subject.Name)
+	case Moderator:
+		return slices.Contains(resource.Moderators, subject.Name)
+	case Staff:
+		return slices.Contains(resource.Instructors, subject.Name) || slices.Contains(resource.Moderators, subject.Name)
+	case Enrolled:
+		return slices.Contains(resource.Students, subject.Name)
+	}
+	return false
+}
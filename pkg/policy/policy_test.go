@@ -0,0 +1,138 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	course := Resource{
+		Kind:        "course",
+		ID:          "advanced-golang",
+		Instructors: []string{"arnob"},
+		Moderators:  []string{"sunny"},
+		Students:    []string{"student-1"},
+	}
+	lesson := course
+	lesson.Kind, lesson.ID = "lesson", "introduction"
+	progress := course
+	progress.Kind, progress.ID = "progress", "student-1"
+	exam := course
+	exam.Kind, exam.ID = "exam", "midterm"
+	quiz := course
+	quiz.Kind, quiz.ID = "content", "quiz-1"
+	question := course
+	question.Kind, question.ID = "question", "q-1"
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   string
+		resource Resource
+		expected bool
+	}{
+		{name: "AdminOverridesEverything", subject: Subject{Name: "admin", Roles: []string{"admin"}}, action: "delete", resource: course, expected: true},
+		{name: "StudentReadsCourse", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "read", resource: course, expected: true},
+		{name: "StudentCannotCreateCourse", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "create", resource: Resource{Kind: "course"}, expected: false},
+		{name: "TrainerCreatesCourse", subject: Subject{Name: "trainer-1", Roles: []string{"trainer"}}, action: "create", resource: Resource{Kind: "course"}, expected: true},
+		{name: "InstructorUpdatesOwnCourse", subject: Subject{Name: "arnob", Roles: []string{"trainer"}}, action: "update", resource: course, expected: true},
+		{name: "CourseModeratorUpdatesCourse", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "update", resource: course, expected: true},
+		{name: "OtherModeratorCannotUpdateCourse", subject: Subject{Name: "moderator-2", Roles: []string{"moderator"}}, action: "update", resource: course, expected: false},
+		{name: "OtherModeratorCannotAddLesson", subject: Subject{Name: "someone", Roles: []string{"moderator"}}, action: "create", resource: lesson, expected: false},
+		{name: "CourseModeratorAddsLesson", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "create", resource: lesson, expected: true},
+		{name: "EnrolledStudentReadsLesson", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "read", resource: lesson, expected: true},
+		{name: "OtherStudentCannotReadLesson", subject: Subject{Name: "student-2", Roles: []string{"student"}}, action: "read", resource: lesson, expected: false},
+		{name: "CourseModeratorReadsLesson", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "read", resource: lesson, expected: true},
+		{name: "StudentEnrolls", subject: Subject{Name: "student-2", Roles: []string{"student"}}, action: "enroll", resource: course, expected: true},
+		{name: "StudentCannotManageStudents", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "manage_students", resource: course, expected: false},
+		{name: "InstructorManagesStudents", subject: Subject{Name: "arnob", Roles: []string{"trainer"}}, action: "manage_students", resource: course, expected: true},
+		{name: "StudentReadsOwnProgress", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "read", resource: progress, expected: true},
+		{name: "OtherStudentCannotReadProgress", subject: Subject{Name: "student-2", Roles: []string{"student"}}, action: "read", resource: progress, expected: false},
+		{name: "CourseModeratorReadsProgress", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "read", resource: progress, expected: true},
+		{name: "StudentAttemptsExam", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "attempt", resource: exam, expected: true},
+		{name: "OutsiderCannotAttemptExam", subject: Subject{Name: "student-2", Roles: []string{"student"}}, action: "attempt", resource: exam, expected: false},
+		{name: "StudentCannotUpdateExam", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "update", resource: exam, expected: false},
+		{name: "CourseModeratorCreatesExam", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "create", resource: exam, expected: true},
+		{name: "StudentAttemptsQuiz", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "attempt", resource: quiz, expected: true},
+		{name: "CourseModeratorCannotAttemptQuiz", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "attempt", resource: quiz, expected: false},
+		{name: "StudentCannotReadQuestionBank", subject: Subject{Name: "student-1", Roles: []string{"student"}}, action: "read", resource: question, expected: false},
+		{name: "CourseModeratorUpdatesQuestion", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "update", resource: question, expected: true},
+		{name: "AdminSetsBaseRole", subject: Subject{Name: "admin", Roles: []string{"admin"}}, action: "set_base", resource: Resource{Kind: "role"}, expected: true},
+		{name: "ModeratorCannotSetBaseRole", subject: Subject{Name: "sunny", Roles: []string{"moderator"}}, action: "set_base", resource: Resource{Kind: "role"}, expected: false},
+		{name: "InstructorCannotDeleteCourse", subject: Subject{Name: "arnob", Roles: []string{"trainer"}}, action: "delete", resource: course, expected: false},
+		{name: "NoRoleIsDenied", subject: Subject{Roles: []string{""}}, action: "read", resource: course, expected: false},
+	}
+
+	p := Default()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := p.Decide(test.subject, test.action, test.resource).Allowed; got != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnknownCondition(t *testing.T) {
+	_, err := Parse([]byte(`{"rules": [{"roles": ["*"], "resource": "course", "actions": ["read"], "conditions": ["friend"]}]}`))
+	if err == nil {
+		t.Fatal("expected an error for an unknown condition")
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"rules": [{"roles": ["student"], "resource": "course", "actions": ["read"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	student := Subject{Name: "student-1", Roles: []string{"student"}}
+	if !engine.Decide(student, "read", Resource{Kind: "course"}).Allowed {
+		t.Fatal("expected the student to read courses")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"rules": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if engine.Decide(student, "read", Resource{Kind: "course"}).Allowed {
+		t.Fatal("expected the reloaded policy to deny everything")
+	}
+
+	if err := os.WriteFile(path, []byte(`not json`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Reload(); err == nil {
+		t.Fatal("expected an error for an invalid policy")
+	}
+}
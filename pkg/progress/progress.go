@@ -0,0 +1,185 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package progress turns the progress students report on contents into lesson & course completion.
+package progress
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of progress events
+const (
+	Started   = "started"
+	Watched   = "watched" // Percent of the content was watched or read, up to Position
+	Completed = "completed"
+)
+
+// Event is progress a student reports on a content.
+type Event struct {
+	Type     string  `json:"type"`
+	Percent  int     `json:"percent"`
+	Position float64 `json:"position"` // where to resume, e.g. seconds into a video
+}
+
+// Completes reports whether the event completes the content.
+func (e Event) Completes() bool {
+	return e.Type == Completed || e.Percent == 100
+}
+
+func (e Event) Validate() error {
+	switch e.Type {
+	case Started, Watched, Completed:
+	default:
+		return fmt.Errorf("unknown progress event '%s', expected %s, %s or %s", e.Type, Started, Watched, Completed)
+	}
+	if e.Percent < 0 || e.Percent > 100 {
+		return fmt.Errorf("percent must be between 0 and 100")
+	}
+	if e.Position < 0 {
+		return fmt.Errorf("position can't be negative")
+	}
+	return nil
+}
+
+// Lesson is a lesson of a course, with its contents in order.
+type Lesson struct {
+	ID       string
+	Title    string
+	Contents []string
+}
+
+// Record is the progress of a student on a content.
+type Record struct {
+	Content   string
+	Percent   int
+	Position  float64
+	Completed bool
+	UpdatedAt time.Time
+}
+
+func (r Record) percent() int {
+	if r.Completed {
+		return 100
+	}
+	return r.Percent
+}
+
+type LessonSummary struct {
+	Lesson    string `json:"lesson"`
+	Title     string `json:"title"`
+	Percent   int    `json:"percent"`
+	Completed int    `json:"completed"`
+	Total     int    `json:"total"`
+}
+
+// Resume is where the student left off.
+type Resume struct {
+	Lesson   string  `json:"lesson"`
+	Content  string  `json:"content"`
+	Position float64 `json:"position"`
+}
+
+type Summary struct {
+	Percent   int             `json:"percent"`
+	Completed int             `json:"completed"`
+	Total     int             `json:"total"`
+	Lessons   []LessonSummary `json:"lessons"`
+	Resume    *Resume         `json:"resume,omitempty"` // nil once every content is completed
+}
+
+type position struct {
+	lesson  string
+	content string
+}
+
+// Summarize aggregates the records of a student to the lessons & the course. Every content weighs the same,
+// partly watched ones count for their percentage. Records of contents no longer in the course are ignored.
+func Summarize(lessons []Lesson, records []Record) Summary {
+	byContent := make(map[string]Record, len(records))
+	for _, record := range records {
+		byContent[record.Content] = record
+	}
+
+	summary := Summary{Lessons: make([]LessonSummary, 0, len(lessons))}
+	var order []position
+	var latest *Record
+	sum := 0
+	for _, lesson := range lessons {
+		ls := LessonSummary{Lesson: lesson.ID, Title: lesson.Title, Total: len(lesson.Contents)}
+		lessonSum := 0
+		for _, content := range lesson.Contents {
+			order = append(order, position{lesson: lesson.ID, content: content})
+			record, ok := byContent[content]
+			if !ok {
+				continue
+			}
+			lessonSum += record.percent()
+			if record.Completed {
+				ls.Completed++
+			}
+			if latest == nil || record.UpdatedAt.After(latest.UpdatedAt) {
+				latest = &record
+			}
+		}
+		ls.Percent = percentOf(lessonSum, ls.Total)
+		summary.Lessons = append(summary.Lessons, ls)
+		summary.Completed += ls.Completed
+		summary.Total += ls.Total
+		sum += lessonSum
+	}
+	summary.Percent = percentOf(sum, summary.Total)
+	summary.Resume = resume(order, byContent, latest)
+	return summary
+}
+
+// resume picks the content touched last if it isn't completed yet.
+// Otherwise, the first content after it which isn't, wrapping around to the start of the course.
+func resume(order []position, byContent map[string]Record, latest *Record) *Resume {
+	start := 0
+	for i, p := range order {
+		if latest != nil && p.content == latest.Content {
+			if !latest.Completed {
+				return &Resume{Lesson: p.lesson, Content: p.content, Position: latest.Position}
+			}
+			start = i + 1
+			break
+		}
+	}
+	for i := range order {
+		p := order[(start+i)%len(order)]
+		if record, ok := byContent[p.content]; !ok || !record.Completed {
+			return &Resume{Lesson: p.lesson, Content: p.content, Position: byContent[p.content].Position}
+		}
+	}
+	return nil
+}
+
+func percentOf(sum int, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
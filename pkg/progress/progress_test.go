@@ -0,0 +1,154 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package progress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	lessons := []Lesson{
+		{ID: "basics", Contents: []string{"intro", "setup"}},
+		{ID: "advanced", Contents: []string{"generics", "channels"}},
+	}
+	now := time.Now()
+	tests := []struct {
+		name          string
+		records       []Record
+		percent       int
+		lessonPercent int
+		completed     int
+		resume        *Resume
+	}{
+		{
+			name:    "NothingStarted",
+			percent: 0, lessonPercent: 0, completed: 0,
+			resume: &Resume{Lesson: "basics", Content: "intro"},
+		},
+		{
+			name: "ResumesPartlyWatched",
+			records: []Record{
+				{Content: "intro", Completed: true, UpdatedAt: now.Add(-time.Hour)},
+				{Content: "setup", Percent: 50, Position: 42, UpdatedAt: now},
+			},
+			percent: 37, lessonPercent: 75, completed: 1,
+			resume: &Resume{Lesson: "basics", Content: "setup", Position: 42},
+		},
+		{
+			name: "NextAfterCompleted",
+			records: []Record{
+				{Content: "intro", Completed: true, UpdatedAt: now.Add(-time.Hour)},
+				{Content: "setup", Completed: true, UpdatedAt: now},
+			},
+			percent: 50, lessonPercent: 100, completed: 2,
+			resume: &Resume{Lesson: "advanced", Content: "generics"},
+		},
+		{
+			name: "WrapsAround",
+			records: []Record{
+				{Content: "channels", Completed: true, UpdatedAt: now},
+				{Content: "generics", Completed: true, UpdatedAt: now.Add(-time.Hour)},
+			},
+			percent: 50, lessonPercent: 0, completed: 2,
+			resume: &Resume{Lesson: "basics", Content: "intro"},
+		},
+		{
+			name: "EverythingCompleted",
+			records: []Record{
+				{Content: "intro", Completed: true}, {Content: "setup", Completed: true},
+				{Content: "generics", Completed: true}, {Content: "channels", Completed: true},
+			},
+			percent: 100, lessonPercent: 100, completed: 4,
+		},
+		{
+			name:    "IgnoresRemovedContent",
+			records: []Record{{Content: "removed", Completed: true, UpdatedAt: now}},
+			percent: 0, lessonPercent: 0, completed: 0,
+			resume: &Resume{Lesson: "basics", Content: "intro"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			summary := Summarize(lessons, test.records)
+			if summary.Percent != test.percent {
+				t.Fatalf("expected %v, got %v", test.percent, summary.Percent)
+			}
+			if summary.Lessons[0].Percent != test.lessonPercent {
+				t.Fatalf("expected %v, got %v", test.lessonPercent, summary.Lessons[0].Percent)
+			}
+			if summary.Completed != test.completed {
+				t.Fatalf("expected %v, got %v", test.completed, summary.Completed)
+			}
+			if (summary.Resume == nil) != (test.resume == nil) || (test.resume != nil && *summary.Resume != *test.resume) {
+				t.Fatalf("expected %v, got %v", test.resume, summary.Resume)
+			}
+		})
+	}
+}
+
+func TestEventValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		valid bool
+	}{
+		{name: "Started", event: Event{Type: Started}, valid: true},
+		{name: "Watched", event: Event{Type: Watched, Percent: 40, Position: 120}, valid: true},
+		{name: "UnknownType", event: Event{Type: "paused"}, valid: false},
+		{name: "PercentTooHigh", event: Event{Type: Watched, Percent: 101}, valid: false},
+		{name: "NegativePosition", event: Event{Type: Watched, Position: -1}, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if valid := test.event.Validate() == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v", test.valid, valid)
+			}
+		})
+	}
+}
+
+func TestEventCompletes(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     Event
+		completes bool
+	}{
+		{name: "Completed", event: Event{Type: Completed}, completes: true},
+		{name: "WatchedAll", event: Event{Type: Watched, Percent: 100}, completes: true},
+		{name: "WatchedPart", event: Event{Type: Watched, Percent: 99}, completes: false},
+		{name: "Started", event: Event{Type: Started}, completes: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if completes := test.event.Completes(); completes != test.completes {
+				t.Fatalf("expected %v, got %v", test.completes, completes)
+			}
+		})
+	}
+}
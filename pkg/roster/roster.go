@@ -0,0 +1,142 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package roster reads the cohort lists partners send us, one user per CSV row.
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"slices"
+	"strings"
+)
+
+// MaxRows is the most users a single roster may hold.
+const MaxRows = 5000
+
+// Columns a roster may have. The header row names them, in any order & case.
+// userName & email are required, the others are optional.
+const (
+	ColumnUserName    = "username"
+	ColumnEmail       = "email"
+	ColumnPhone       = "phone"
+	ColumnDisplayName = "displayname"
+	ColumnRole        = "role"
+)
+
+var knownColumns = []string{ColumnUserName, ColumnEmail, ColumnPhone, ColumnDisplayName, ColumnRole}
+
+// Row is one user of the roster. Err is set if the row itself is malformed.
+type Row struct {
+	Line        int
+	UserName    string
+	Email       string
+	Phone       string
+	DisplayName string
+	Role        string
+	Err         error
+}
+
+// Parse reads the roster. Malformed rows are returned with their error, so that they can be reported
+// along with the others. An error is only returned if the file as a whole can't be read.
+func Parse(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the roster is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(knownColumns, name) {
+			return nil, fmt.Errorf("unknown column '%s', expected some of %s", name, strings.Join(knownColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate column '%s'", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{ColumnUserName, ColumnEmail} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the '%s' column is required", name)
+		}
+	}
+
+	var rows []Row
+	userNames, emails := map[string]int{}, map[string]int{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("the roster has more than %d rows", MaxRows)
+		}
+		row := Row{Line: line, Err: err}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row.UserName, row.Email, row.Phone = field(ColumnUserName), field(ColumnEmail), field(ColumnPhone)
+		row.DisplayName, row.Role = field(ColumnDisplayName), strings.ToLower(field(ColumnRole))
+		if row.Err == nil {
+			row.Err = validate(row, userNames, emails)
+		}
+		if row.Err == nil {
+			userNames[row.UserName] = row.Line
+			emails[strings.ToLower(row.Email)] = row.Line
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func validate(row Row, userNames map[string]int, emails map[string]int) error {
+	if row.UserName == "" || strings.ContainsAny(row.UserName, "/ ") {
+		return fmt.Errorf("invalid user name '%s'", row.UserName)
+	}
+	if _, err := mail.ParseAddress(row.Email); err != nil {
+		return fmt.Errorf("invalid email '%s'", row.Email)
+	}
+	if line, ok := userNames[row.UserName]; ok {
+		return fmt.Errorf("the user name '%s' is already on line %d", row.UserName, line)
+	}
+	if line, ok := emails[strings.ToLower(row.Email)]; ok {
+		return fmt.Errorf("the email '%s' is already on line %d", row.Email, line)
+	}
+	return nil
+}
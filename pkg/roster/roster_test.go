@@ -0,0 +1,103 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package roster
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rows, err := Parse(strings.NewReader(`Email,userName,Role
+alice@uni.edu,alice,student
+bob@uni.edu,bob
+not-an-email,carol,student
+ALICE@uni.edu,alice2,student
+dave@uni.edu,alice,student
+eve@uni.edu,e ve,student
+`))
+	if err != nil {
+		t.Fatalf("expected %v, got %v", nil, err)
+	}
+	tests := []struct {
+		name     string
+		row      Row
+		userName string
+		role     string
+		line     int
+		valid    bool
+	}{
+		{name: "Valid", row: rows[0], userName: "alice", role: "student", line: 2, valid: true},
+		{name: "MissingField", row: rows[1], userName: "bob", line: 3, valid: false},
+		{name: "InvalidEmail", row: rows[2], userName: "carol", role: "student", line: 4, valid: false},
+		{name: "DuplicateEmail", row: rows[3], userName: "alice2", role: "student", line: 5, valid: false},
+		{name: "DuplicateUserName", row: rows[4], userName: "alice", role: "student", line: 6, valid: false},
+		{name: "InvalidUserName", row: rows[5], userName: "e ve", role: "student", line: 7, valid: false},
+	}
+	if len(rows) != len(tests) {
+		t.Fatalf("expected %v, got %v", len(tests), len(rows))
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.row.UserName != test.userName {
+				t.Fatalf("expected %v, got %v", test.userName, test.row.UserName)
+			}
+			if test.row.Role != test.role {
+				t.Fatalf("expected %v, got %v", test.role, test.row.Role)
+			}
+			if test.row.Line != test.line {
+				t.Fatalf("expected %v, got %v", test.line, test.row.Line)
+			}
+			if valid := test.row.Err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, test.row.Err)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		csv   string
+		valid bool
+	}{
+		{name: "Minimal", csv: "userName,email\n", valid: true},
+		{name: "ByteOrderMark", csv: "\ufeffuserName,email,phone,displayName\n", valid: true},
+		{name: "Empty", csv: "", valid: false},
+		{name: "MissingEmail", csv: "userName,phone\n", valid: false},
+		{name: "UnknownColumn", csv: "userName,email,password\n", valid: false},
+		{name: "DuplicateColumn", csv: "userName,email,Email\n", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.csv))
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected %v, got %v (%v)", test.valid, valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/cors"
+)
+
+const (
+	Development = "development"
+	Staging     = "staging"
+	Production  = "production"
+)
+
+// Config is the browser facing security policy of the server: which origins may call
+// the API with credentials, and which security headers every response carries.
+type Config struct {
+	AllowedOrigins        []string `json:"allowedOrigins"`
+	HSTSMaxAge            Duration `json:"hstsMaxAge"`
+	HSTSIncludeSubdomains bool     `json:"hstsIncludeSubdomains"`
+	ContentSecurityPolicy string   `json:"contentSecurityPolicy"`
+	FrameOptions          string   `json:"frameOptions"`
+	ReferrerPolicy        string   `json:"referrerPolicy"`
+}
+
+// Duration is a time.Duration written as a string like "8760h" in config files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Preset returns the built-in config of an environment.
+func Preset(env string) (Config, error) {
+	base := Config{
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+	}
+	switch env {
+	case Development:
+		base.AllowedOrigins = []string{"http://localhost:*", "http://127.0.0.1:*"}
+	case Staging, Production:
+		base.HSTSMaxAge = Duration(365 * 24 * time.Hour)
+		base.HSTSIncludeSubdomains = true
+	default:
+		return Config{}, fmt.Errorf("unknown environment '%s'", env)
+	}
+	return base, nil
+}
+
+// Load returns the preset of env, overridden by the fields set in the JSON file at path, if any.
+func Load(env string, path string) (Config, error) {
+	c, err := Preset(env)
+	if err != nil {
+		return Config{}, err
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		if err := json.Unmarshal(data, &c); err != nil {
+			return Config{}, fmt.Errorf("invalid security config: %w", err)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// Validate rejects origins which would let any site make credentialed requests.
+// A wildcard may stand for the subdomains of a host, or for its port.
+func (c Config) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			return fmt.Errorf("origin '*' is not allowed with credentials, list the origins instead")
+		}
+		// Any port, the host is checked like any other.
+		u, err := url.Parse(strings.Replace(strings.TrimSuffix(origin, ":*"), "*", "wildcard", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid origin '%s', expected scheme://host[:port]", origin)
+		}
+		if strings.HasPrefix(u.Host, "wildcard") && !strings.HasPrefix(u.Host, "wildcard.") {
+			return fmt.Errorf("origin '%s' matches any host", origin)
+		}
+		if u.Path != "" {
+			return fmt.Errorf("invalid origin '%s', origins have no path", origin)
+		}
+	}
+	return nil
+}
+
+// CORSOptions returns the CORS settings allowing credentialed requests from the allowed origins only.
+func (c Config) CORSOptions() cors.Options {
+	opts := cors.Options{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link", "X-CSRF-Token"},
+		AllowCredentials: true,
+		MaxAge:           300, // Maximum value not ignored by any of major browsers
+	}
+	if len(opts.AllowedOrigins) == 0 {
+		// An empty list means every origin to the cors package.
+		opts.AllowOriginFunc = func(r *http.Request, origin string) bool { return false }
+	}
+	return opts
+}
+
+// Headers is a middleware setting the configured security headers on every response.
+func (c Config) Headers(next http.Handler) http.Handler {
+	hsts := ""
+	if c.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(time.Duration(c.HSTSMaxAge)/time.Second), 10)
+		if c.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		if c.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", c.ContentSecurityPolicy)
+		}
+		if c.FrameOptions != "" {
+			h.Set("X-Frame-Options", c.FrameOptions)
+		}
+		if c.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", c.ReferrerPolicy)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,153 @@
+/*
+MIT License
+
+Copyright (c) 2024 Praromvik
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/cors"
+)
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected map[string]string
+	}{
+		{
+			name:   "Development",
+			config: mustPreset(t, Development),
+			expected: map[string]string{
+				"Strict-Transport-Security": "",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "strict-origin-when-cross-origin",
+				"X-Content-Type-Options":    "nosniff",
+			},
+		},
+		{
+			name:   "Production",
+			config: mustPreset(t, Production),
+			expected: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+			},
+		},
+		{
+			name:   "Custom",
+			config: Config{HSTSMaxAge: Duration(time.Hour), FrameOptions: "SAMEORIGIN"},
+			expected: map[string]string{
+				"Strict-Transport-Security": "max-age=3600",
+				"X-Frame-Options":           "SAMEORIGIN",
+				"Content-Security-Policy":   "",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := test.config.Headers(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			for header, expected := range test.expected {
+				if got := rec.Header().Get(header); got != expected {
+					t.Fatalf("%s: expected %q, got %q", header, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		origin   string
+		expected string
+	}{
+		{name: "DevelopmentLocalhost", env: Development, origin: "http://localhost:3000", expected: "http://localhost:3000"},
+		{name: "DevelopmentForeign", env: Development, origin: "https://evil.example", expected: ""},
+		{name: "ProductionWithoutOrigins", env: Production, origin: "http://localhost:3000", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := cors.Handler(mustPreset(t, test.env).CORSOptions())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", test.origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		valid   bool
+	}{
+		{name: "Exact", origins: []string{"https://praromvik.com"}, valid: true},
+		{name: "Subdomains", origins: []string{"https://*.praromvik.com"}, valid: true},
+		{name: "AnyPort", origins: []string{"http://localhost:*"}, valid: true},
+		{name: "AnyHostAnyPort", origins: []string{"http://*:*"}, valid: false},
+		{name: "Star", origins: []string{"*"}, valid: false},
+		{name: "AnyHTTPS", origins: []string{"https://*"}, valid: false},
+		{name: "WithPath", origins: []string{"https://praromvik.com/app"}, valid: false},
+		{name: "NoScheme", origins: []string{"praromvik.com"}, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Config{AllowedOrigins: test.origins}.Validate()
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid %v, got error %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestLoadPresets(t *testing.T) {
+	for _, env := range []string{Development, Staging, Production} {
+		t.Run(env, func(t *testing.T) {
+			if _, err := Load(env, ""); err != nil {
+				t.Fatalf("expected the %s preset to load, got %v", env, err)
+			}
+		})
+	}
+}
+
+func mustPreset(t *testing.T, env string) Config {
+	c, err := Preset(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
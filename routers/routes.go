@@ -27,79 +27,274 @@ package routers
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"github.com/praromvik/praromvik/handlers/asset"
+	"github.com/praromvik/praromvik/handlers/badge"
+	"github.com/praromvik/praromvik/handlers/certificate"
 	"github.com/praromvik/praromvik/handlers/course"
+	"github.com/praromvik/praromvik/handlers/session"
+	"github.com/praromvik/praromvik/handlers/token"
 	"github.com/praromvik/praromvik/handlers/user"
+	"github.com/praromvik/praromvik/models/utils"
 	middleware "github.com/praromvik/praromvik/pkg/middileware"
+	"github.com/praromvik/praromvik/pkg/policy"
+	"github.com/praromvik/praromvik/pkg/security"
 )
 
-func LoadRoutes() *chi.Mux {
+func LoadRoutes(engine *policy.Engine, sec security.Config) *chi.Mux {
+	authz := middleware.Authorizer{Engine: engine}
 	router := chi.NewRouter()
-	router.Use(cors.Handler(cors.Options{
-		// AllowedOrigins:   []string{"https://foo.com"}, // Use this to allow specific origin hosts
-		AllowedOrigins: []string{"https://*", "http://*"},
-		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300, // Maximum value not ignored by any of major browsers
-	}))
+	router.Use(cors.Handler(sec.CORSOptions()))
+	router.Use(sec.Headers)
 	// Apply global middleware
 	middleware.AddMiddlewares(router)
+	router.Use(middleware.CSRFProtect)
 
 	router.Group(func(r chi.Router) {
-		loadUserAuthRoutes(r)
+		loadUserAuthRoutes(r, authz)
 	})
-	router.With(middleware.AdminAccess).Post("/api/role", user.User{}.ProvideRoleToUser)
+	router.With(middleware.SecurityMiddleware, authz.Allow(utils.ResourceUser, utils.ActionUnlock)).Delete("/api/user/{userName}/lock", user.User{}.Unlock)
+	router.With(middleware.SecurityMiddleware, middleware.SessionOnly,
+		authz.Allow(utils.ResourceUser, utils.ActionImpersonate)).Post("/api/user/{userName}/impersonate", user.User{}.Impersonate)
+	router.With(middleware.AllowDuringImpersonation, middleware.SecurityMiddleware).Delete("/api/impersonation", user.User{}.StopImpersonation)
 
-	router.Route("/api/course", loadCourseRoutes)
+	router.Route("/api/course", func(r chi.Router) {
+		loadCourseRoutes(r, authz)
+	})
+	router.Route("/api/role", func(r chi.Router) {
+		loadRoleRoutes(r, authz)
+	})
+	router.Route("/api/user/me", loadAccountRoutes)
+	router.Get("/api/user/email/confirm", user.User{}.ConfirmEmail)
+	router.Get("/api/user/invitation", user.User{}.GetInvitation)
+	router.Post("/api/user/invitation", user.User{}.AcceptInvitation)
+	router.Get("/api/assets/{id}", asset.Asset{}.Get)
+	router.Get("/verify/{serial}", certificate.Certificate{}.Verify)
+	router.Get("/verify/{serial}/proof", certificate.Certificate{}.Proof)
+	router.Get("/api/ledger/key", certificate.Certificate{}.LedgerKey)
+	router.Get("/api/ledger/checkpoint", certificate.Certificate{}.LedgerCheckpoint)
+	router.Route("/api/badges", loadBadgeRoutes)
+	router.Route("/api/admin/deletions", func(r chi.Router) {
+		loadDeletionRoutes(r, authz)
+	})
+	router.Route("/api/admin/users", func(r chi.Router) {
+		loadDirectoryRoutes(r, authz)
+	})
+	router.Route("/api/tokens", loadTokenRoutes)
+	router.Route("/api/sessions", loadSessionRoutes)
+	router.With(middleware.AllowDuringPasswordReset, middleware.SecurityMiddleware, middleware.SessionOnly).Get("/api/csrf", session.Session{}.CSRF)
 	return router
 }
-func loadUserAuthRoutes(r chi.Router) {
+func loadAccountRoutes(r chi.Router) {
+	handler := user.User{}
+	// Users who must reset their password can still change it.
+	r.With(middleware.AllowDuringPasswordReset, middleware.SecurityMiddleware, middleware.SessionOnly).Patch("/", handler.UpdateMe)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.SecurityMiddleware, middleware.SessionOnly)
+		r.Post("/avatar", handler.UploadAvatar)
+		r.Get("/export", handler.Export)
+		r.Get("/certificates", certificate.Certificate{}.List)
+		r.Post("/deletion", handler.RequestDeletion)
+		r.Delete("/deletion", handler.CancelDeletion)
+	})
+}
+
+func loadBadgeRoutes(r chi.Router) {
+	handler := badge.Badge{}
+	r.Get("/issuer", handler.Issuer)
+	r.Get("/courses/{id}", handler.Achievement)
+	r.Get("/courses/{id}/image.{format}", handler.Image)
+	r.Get("/assertions/{serial}", handler.Assertion)
+	r.Get("/assertions/{serial}/badge.{format}", handler.AssertionImage)
+	r.Get("/credentials/{serial}", handler.Credential)
+	r.Get("/credentials/{serial}/badge.{format}", handler.CredentialImage)
+}
+
+func loadDeletionRoutes(r chi.Router, authz middleware.Authorizer) {
+	handler := user.User{}
+	r.Use(middleware.SecurityMiddleware, middleware.SessionOnly, authz.Allow(utils.ResourceUser, utils.ActionDelete))
+	r.Get("/", handler.PendingDeletions)
+	r.Post("/purge", handler.PurgeDeletions)
+}
+
+func loadDirectoryRoutes(r chi.Router, authz middleware.Authorizer) {
+	handler := user.User{}
+	r.Use(middleware.SecurityMiddleware, middleware.SessionOnly)
+	r.With(authz.Allow(utils.ResourceUser, utils.ActionList)).Get("/", handler.Directory)
+	r.With(authz.Allow(utils.ResourceUser, utils.ActionCreate)).Post("/import", handler.Import)
+	r.Group(func(r chi.Router) {
+		r.Use(authz.Allow(utils.ResourceUser, utils.ActionModerate))
+		r.Post("/{userName}/suspend", handler.Suspend)
+		r.Post("/{userName}/ban", handler.Ban)
+		r.Post("/{userName}/reactivate", handler.Reactivate)
+		r.Post("/{userName}/force-password-reset", handler.ForcePasswordReset)
+	})
+}
+
+func loadUserAuthRoutes(r chi.Router, authz middleware.Authorizer) {
 	userHandler := &user.User{}
 	r.Post("/api/signup", userHandler.SignUp)
 	r.Post("/api/signin", userHandler.SignIn)
 	r.Delete("/api/signout", userHandler.SignOut)
-	r.With(middleware.SecurityMiddleware).Get("/api/user/{userName}", userHandler.Get)
+	r.With(middleware.SecurityMiddleware, middleware.RequireScope(utils.ScopeUserRead),
+		authz.Allow(utils.ResourceUser, utils.ActionRead)).Get("/api/user/{userName}", userHandler.Get)
 }
 
-func loadCourseRoutes(r chi.Router) {
+func loadRoleRoutes(r chi.Router, authz middleware.Authorizer) {
 	r.Use(middleware.SecurityMiddleware)
-	r.Route("/{courseRef}/lesson", loadLessonRoutes)
-	r.Route("/{courseRef}/content", loadContentRoutes)
+
+	handler := user.User{}
+	r.With(authz.Allow(utils.ResourceRole, utils.ActionRead)).Get("/{role}/users", handler.ListByRole)
+	r.With(authz.Allow(utils.ResourceRole, utils.ActionGrant)).Post("/grant", handler.GrantRole)
+	r.With(authz.Allow(utils.ResourceRole, utils.ActionRevoke)).Post("/revoke", handler.RevokeRole)
+	r.With(authz.Allow(utils.ResourceRole, utils.ActionSetBase)).Post("/base", handler.SetBaseRole)
+}
+
+func loadTokenRoutes(r chi.Router) {
+	r.Use(middleware.SecurityMiddleware)
+	// Tokens can't be used to mint or revoke other tokens.
+	r.Use(middleware.SessionOnly)
+
+	handler := &token.Token{}
+	r.Post("/", handler.Create)
+	r.Get("/", handler.List)
+	r.Delete("/{id}", handler.Revoke)
+}
+
+func loadSessionRoutes(r chi.Router) {
+	r.Use(middleware.SecurityMiddleware)
+	r.Use(middleware.SessionOnly)
+
+	handler := session.Session{}
+	r.Get("/", handler.List)
+	r.Delete("/", handler.RevokeAll)
+	r.Delete("/{id}", handler.Revoke)
+}
+
+func loadCourseRoutes(r chi.Router, authz middleware.Authorizer) {
+	r.Use(middleware.SecurityMiddleware)
+	r.Route("/{courseRef}/lesson", func(r chi.Router) {
+		loadLessonRoutes(r, authz)
+	})
+	r.Route("/{courseRef}/content", func(r chi.Router) {
+		loadContentRoutes(r, authz)
+	})
+	r.Route("/{courseRef}/progress", func(r chi.Router) {
+		loadProgressRoutes(r, authz)
+	})
+	r.Route("/{courseRef}/exam", func(r chi.Router) {
+		loadExamRoutes(r, authz)
+	})
+	r.Route("/{courseRef}/questions", func(r chi.Router) {
+		loadQuestionRoutes(r, authz)
+	})
 
 	handler := &course.Course{}
-	r.Get("/list", handler.List)
-	r.Get("/{id}", handler.Get)
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AdminOrModeratorAccess)
-		r.Post("/", handler.Create)
-		r.Put("/{id}", handler.Update)
+		r.Use(middleware.RequireScope(utils.ScopeCourseRead))
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionRead)).Get("/list", handler.List)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionRead)).Get("/{id}", handler.Get)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionEnroll)).Get("/{id}/waitlist/me", handler.WaitlistStatus)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionManageStudents)).Get("/{id}/waitlist", handler.ListWaitlist)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeCourseWrite))
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionCreate)).Post("/", handler.Create)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionUpdate)).Put("/{id}", handler.Update)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionDelete)).Delete("/{id}", handler.Delete)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeCourseWrite))
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionEnroll)).Post("/{id}/enrollment", handler.Enroll)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionEnroll)).Delete("/{id}/enrollment", handler.Unenroll)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionManageStudents)).Post("/{id}/students/{userName}", handler.EnrollStudent)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionManageStudents)).Delete("/{id}/students/{userName}", handler.UnenrollStudent)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionEnroll)).Post("/{id}/waitlist", handler.JoinWaitlist)
+		r.With(authz.Allow(utils.ResourceCourse, utils.ActionEnroll)).Delete("/{id}/waitlist", handler.LeaveWaitlist)
 	})
-	//Require admin access
-	r.With(middleware.AdminAccess).Delete("/{id}", handler.Delete)
 }
 
-func loadLessonRoutes(r chi.Router) {
+func loadLessonRoutes(r chi.Router, authz middleware.Authorizer) {
 	handler := &course.Lesson{}
-	r.Get("/list", handler.List)
-	r.Get("/{id}", handler.Get)
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AdminOrModeratorAccess)
-		r.Post("/", handler.Create)
+		r.Use(middleware.RequireScope(utils.ScopeLessonRead))
+		r.Use(authz.Allow(utils.ResourceLesson, utils.ActionRead))
+		r.Get("/list", handler.List)
+		r.Get("/{id}", handler.Get)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeLessonWrite))
+		r.With(authz.Allow(utils.ResourceLesson, utils.ActionCreate)).Post("/", handler.Create)
+		r.With(authz.Allow(utils.ResourceLesson, utils.ActionDelete)).Delete("/{id}", handler.Delete)
 	})
-	//Require admin access
-	r.With(middleware.AdminAccess).Delete("/{id}", handler.Delete)
 }
 
-func loadContentRoutes(r chi.Router) {
+func loadProgressRoutes(r chi.Router, authz middleware.Authorizer) {
+	handler := &course.Course{}
+	r.Use(middleware.RequireScope(utils.ScopeCourseRead))
+	r.With(authz.Allow(utils.ResourceContent, utils.ActionRead)).Get("/", handler.Progress)
+	r.With(authz.Allow(utils.ResourceProgress, utils.ActionRead)).Get("/{userName}", handler.StudentProgress)
+}
+
+func loadExamRoutes(r chi.Router, authz middleware.Authorizer) {
+	handler := &course.Exam{}
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeCourseRead))
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionRead)).Get("/list", handler.List)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionRead)).Get("/{id}", handler.Get)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionAttempt)).Get("/{id}/attempt", handler.Attempt)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionAttempt)).Get("/{id}/attempts", handler.Attempts)
+		r.With(authz.Allow(utils.ResourceSubmission, utils.ActionRead)).Get("/{id}/attempts/{userName}", handler.StudentAttempts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeCourseWrite))
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionCreate)).Post("/", handler.Create)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionUpdate)).Put("/{id}", handler.Update)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionDelete)).Delete("/{id}", handler.Delete)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionAttempt)).Post("/{id}/attempt", handler.Start)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionAttempt)).Put("/{id}/attempt/answers", handler.SaveAnswers)
+		r.With(authz.Allow(utils.ResourceExam, utils.ActionAttempt)).Post("/{id}/attempt/submit", handler.Submit)
+	})
+}
+
+func loadQuestionRoutes(r chi.Router, authz middleware.Authorizer) {
+	handler := &course.Question{}
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeContentRead))
+		r.Use(authz.Allow(utils.ResourceQuestion, utils.ActionRead))
+		r.Get("/", handler.List)
+		r.Get("/{id}", handler.Get)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeContentWrite))
+		r.With(authz.Allow(utils.ResourceQuestion, utils.ActionCreate)).Post("/", handler.Create)
+		r.With(authz.Allow(utils.ResourceQuestion, utils.ActionUpdate)).Put("/{id}", handler.Update)
+		r.With(authz.Allow(utils.ResourceQuestion, utils.ActionDelete)).Delete("/{id}", handler.Delete)
+	})
+}
+
+func loadContentRoutes(r chi.Router, authz middleware.Authorizer) {
 	handler := &course.Content{}
-	r.Get("/list", handler.List)
-	r.Get("/{id}", handler.Get)
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AdminOrModeratorAccess)
-		r.Post("/", handler.Create)
+		r.Use(middleware.RequireScope(utils.ScopeContentRead))
+		r.Use(authz.Allow(utils.ResourceContent, utils.ActionRead))
+		r.Get("/list", handler.List)
+		r.Get("/{id}", handler.Get)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeContentWrite))
+		r.With(authz.Allow(utils.ResourceContent, utils.ActionRead)).Post("/{id}/progress", handler.RecordProgress)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeContentRead))
+		r.With(authz.Allow(utils.ResourceContent, utils.ActionAttempt)).Get("/{id}/quiz", handler.DrawQuiz)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeContentWrite))
+		r.With(authz.Allow(utils.ResourceContent, utils.ActionAttempt)).Post("/{id}/quiz/submit", handler.SubmitQuiz)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(utils.ScopeContentWrite))
+		r.With(authz.Allow(utils.ResourceContent, utils.ActionCreate)).Post("/", handler.Create)
+		r.With(authz.Allow(utils.ResourceContent, utils.ActionDelete)).Delete("/{id}", handler.Delete)
 	})
-	//Require admin access
-	r.With(middleware.AdminAccess).Delete("/{id}", handler.Delete)
 }